@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"net/url"
+	"os"
+)
+
+const accessibilityURL = "x-apple.systempreferences:com.apple.preference.security?Privacy_Accessibility"
+
+// startCapture starts the input hook once the OS will let it see input.
+// Without accessibility permission on macOS, gohook silently receives
+// nothing, so ask for it first and retry whenever the app regains focus.
+func (a *APMTracker) startCapture() {
+	if accessibilityTrusted() {
+		go a.inputLoop()
+		return
+	}
+	a.inputLog.status("waiting for accessibility permission")
+	if a.headless {
+		fmt.Fprintf(os.Stderr, "error: apmgo is not trusted for accessibility\n%s\n", hookRemedy)
+		os.Exit(1)
+	}
+	a.mutex.Lock()
+	a.awaitingAccess = true
+	a.mutex.Unlock()
+	a.showAccessibilityPrompt()
+}
+
+func (a *APMTracker) recheckAccessibility() {
+	a.mutex.Lock()
+	if !a.awaitingAccess || !accessibilityTrusted() {
+		a.mutex.Unlock()
+		return
+	}
+	a.awaitingAccess = false
+	a.mutex.Unlock()
+	a.inputLog.status("accessibility permission granted")
+	if a.accessDialog != nil {
+		a.accessDialog.Hide()
+	}
+	go a.inputLoop()
+}
+
+func (a *APMTracker) showAccessibilityPrompt() {
+	settings, _ := url.Parse(accessibilityURL)
+	content := container.NewVBox(
+		widget.NewLabel("APM Tracker counts keyboard and mouse input from every app,\n"+
+			"which macOS only allows for apps trusted for accessibility."),
+		widget.NewLabel("Turn on apmgo in the Accessibility list, then come back here;\n"+
+			"counting starts as soon as the permission is granted."),
+		widget.NewHyperlink("Open Accessibility settings", settings),
+	)
+	a.accessDialog = dialog.NewCustom("Accessibility Permission Needed", "Later", content, a.window)
+	a.accessDialog.Show()
+}
@@ -0,0 +1,13 @@
+//go:build darwin
+
+package main
+
+/*
+#cgo LDFLAGS: -framework ApplicationServices
+#include <ApplicationServices/ApplicationServices.h>
+*/
+import "C"
+
+func accessibilityTrusted() bool {
+	return C.AXIsProcessTrusted() != 0
+}
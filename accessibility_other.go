@@ -0,0 +1,8 @@
+//go:build !darwin
+
+package main
+
+// accessibilityTrusted only gates capture on macOS.
+func accessibilityTrusted() bool {
+	return true
+}
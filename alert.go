@@ -0,0 +1,49 @@
+package main
+
+import "time"
+
+const (
+	defaultAlertThreshold = 60
+	defaultAlertDuration  = 30 * time.Second
+)
+
+// checkLowAPM beeps once current APM has stayed below the alert threshold
+// for the configured duration. It stays quiet until APM recovers, so a
+// long lull produces a single alert rather than one per tick.
+func (a *APMTracker) checkLowAPM(stats Stats) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if !a.alertEnabled || stats.Paused || stats.Idle || stats.Current >= a.alertThreshold {
+		a.lowSince = time.Time{}
+		a.alerted = false
+		return
+	}
+	if a.lowSince.IsZero() {
+		a.lowSince = a.now()
+		return
+	}
+	if !a.alerted && a.now().Sub(a.lowSince) >= a.alertDuration {
+		a.alerted = true
+		go beep()
+	}
+}
+
+func (a *APMTracker) getAlertSettings() (enabled bool, threshold int, duration time.Duration) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.alertEnabled, a.alertThreshold, a.alertDuration
+}
+
+func (a *APMTracker) setAlertSettings(enabled bool, threshold int, duration time.Duration) {
+	a.mutex.Lock()
+	a.alertEnabled = enabled
+	a.alertThreshold = max(threshold, 0)
+	if duration > 0 {
+		a.alertDuration = duration
+	}
+	a.lowSince = time.Time{}
+	a.alerted = false
+	a.mutex.Unlock()
+	a.saveConfig()
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/robotn/gohook"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock stands in for time.Now so tests control when actions happen.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+// newTestTracker returns a tracker on a fake clock that keeps whatever it
+// saves in a temporary directory.
+func newTestTracker(t testing.TB) (*APMTracker, *fakeClock) {
+	t.Helper()
+	dataDir = t.TempDir()
+	clock := &fakeClock{time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	a := NewAPMTracker()
+	a.now = clock.now
+	a.startTime = clock.t
+	return a, clock
+}
+
+// atomicRing is the lock-free alternative weighed against RingBuffer's
+// RWMutex: the single writer publishes entries through an atomic count, and
+// readers copy what was published, retrying if the writer lapped them.
+type atomicRing struct {
+	data    []atomic.Int64
+	written atomic.Uint64
+}
+
+func newAtomicRing(capacity int) *atomicRing {
+	return &atomicRing{data: make([]atomic.Int64, capacity)}
+}
+
+func (r *atomicRing) Append(v int64) {
+	n := r.written.Load()
+	r.data[n%uint64(len(r.data))].Store(v)
+	r.written.Store(n + 1)
+}
+
+func (r *atomicRing) GetAll() []int64 {
+	capacity := uint64(len(r.data))
+	for {
+		end := r.written.Load()
+		start := end - min(end, capacity)
+		out := make([]int64, 0, end-start)
+		for i := start; i < end; i++ {
+			out = append(out, r.data[i%capacity].Load())
+		}
+		if r.written.Load()-start <= capacity {
+			return out
+		}
+	}
+}
+
+type timestampRing interface {
+	Append(int64)
+	GetAll() []int64
+}
+
+var ringBuffers = []struct {
+	name string
+	new  func(capacity int) timestampRing
+}{
+	{"mutex", func(capacity int) timestampRing { return NewRingBuffer[int64](capacity) }},
+	{"atomic", func(capacity int) timestampRing { return newAtomicRing(capacity) }},
+}
+
+func BenchmarkRingBufferAppend(b *testing.B) {
+	for _, rb := range ringBuffers {
+		b.Run(rb.name, func(b *testing.B) {
+			r := rb.new(defaultActionCapacity)
+			for i := 0; i < b.N; i++ {
+				r.Append(int64(i))
+			}
+		})
+	}
+}
+
+// BenchmarkRingBufferReadUnderLoad reads a full buffer, as each tick does,
+// while another goroutine appends at 500 actions per second.
+func BenchmarkRingBufferReadUnderLoad(b *testing.B) {
+	for _, rb := range ringBuffers {
+		b.Run(rb.name, func(b *testing.B) {
+			r := rb.new(defaultActionCapacity)
+			for i := 0; i < defaultActionCapacity; i++ {
+				r.Append(int64(i))
+			}
+			stop := make(chan struct{})
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(2 * time.Millisecond)
+				defer ticker.Stop()
+				for i := int64(0); ; i++ {
+					select {
+					case <-stop:
+						return
+					case <-ticker.C:
+						r.Append(i)
+					}
+				}
+			}()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if len(r.GetAll()) != defaultActionCapacity {
+					b.Fatal("short read")
+				}
+			}
+			b.StopTimer()
+			close(stop)
+			<-done
+		})
+	}
+}
+
+// BenchmarkRingBufferScan compares the ways a tick can read a full buffer.
+func BenchmarkRingBufferScan(b *testing.B) {
+	r := NewRingBuffer[int64](3600)
+	for i := 0; i < 3600; i++ {
+		r.Append(int64(i))
+	}
+	b.Run("GetAll", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sum := int64(0)
+			for _, t := range r.GetAll() {
+				sum += t
+			}
+		}
+	})
+	b.Run("GetAllInto", func(b *testing.B) {
+		b.ReportAllocs()
+		var buf []int64
+		for i := 0; i < b.N; i++ {
+			buf = r.GetAllInto(buf)
+			sum := int64(0)
+			for _, t := range buf {
+				sum += t
+			}
+		}
+	})
+	b.Run("ForEach", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sum := int64(0)
+			r.ForEach(func(t int64) { sum += t })
+		}
+	})
+}
+
+// TestStartStopUpdates starts and stops the update loop while input arrives
+// from other goroutines. Run it with -race: running and the timers are shared
+// between the timer goroutine, the input goroutines and shutdown.
+func TestStartStopUpdates(t *testing.T) {
+	for round := 0; round < 5; round++ {
+		dataDir = t.TempDir()
+		a := NewAPMTracker()
+		a.app = test.NewApp()
+		a.window = a.app.NewWindow("")
+		a.currentLabel = widget.NewLabel("")
+		a.miniLabel = newDragLabel("")
+		a.updateInterval = time.Millisecond
+		a.scheduleUpdate()
+
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 200; i++ {
+					a.onAction(KeyboardAction, 30)
+				}
+			}()
+		}
+		wg.Wait()
+		a.stopUpdates()
+
+		// A tick already under way may finish, but none may follow it.
+		time.Sleep(10 * time.Millisecond)
+		before := a.latestStats()
+		a.onAction(KeyboardAction, 30)
+		time.Sleep(10 * time.Millisecond)
+		if after := a.latestStats(); after.TotalActions != before.TotalActions {
+			t.Fatalf("round %d: the update loop ticked after stopping", round)
+		}
+		if got := a.getTotalActions(); got != 801 {
+			t.Fatalf("round %d: total actions = %d, want 801", round, got)
+		}
+	}
+}
+
+func TestAverageAPMAtStartup(t *testing.T) {
+	tests := []struct {
+		elapsed time.Duration
+		want    float64
+	}{
+		{0, 0},
+		{time.Millisecond, 0},
+		{minAverageElapsed - time.Nanosecond, 0},
+		{minAverageElapsed, 120},
+		{time.Minute, 10},
+	}
+	for _, tt := range tests {
+		a, clock := newTestTracker(t)
+		for i := 0; i < 10; i++ {
+			a.addAction(KeyboardAction, 30)
+		}
+		clock.advance(tt.elapsed)
+		if got := a.calculateAverageAPM(); got != tt.want {
+			t.Errorf("after %s: average APM = %v, want %v", tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestRingBufferWraps(t *testing.T) {
+	ints := NewRingBuffer[int64](3)
+	hits := NewRingBuffer[regionHit](3)
+	for i := 1; i <= 5; i++ {
+		ints.Append(int64(i))
+		hits.Append(regionHit{at: int64(i), region: i % 2})
+	}
+	if got := ints.Len(); got != 3 {
+		t.Errorf("int64 Len = %d, want 3", got)
+	}
+	if got, want := ints.GetAll(), []int64{3, 4, 5}; !slices.Equal(got, want) {
+		t.Errorf("int64 GetAll = %v, want %v", got, want)
+	}
+	if got := hits.Len(); got != 3 {
+		t.Errorf("regionHit Len = %d, want 3", got)
+	}
+	want := []regionHit{{3, 1}, {4, 0}, {5, 1}}
+	if got := hits.GetAll(); !slices.Equal(got, want) {
+		t.Errorf("regionHit GetAll = %v, want %v", got, want)
+	}
+}
+
+func TestSubMillisecondActions(t *testing.T) {
+	a, clock := newTestTracker(t)
+	first := clock.now()
+	for i := 0; i < 5; i++ {
+		a.addAction(KeyboardAction, 30)
+		clock.advance(100 * time.Microsecond)
+	}
+	if got := countWithin(a.actions, clock.now(), time.Minute); got != 5 {
+		t.Errorf("counted %d of 5 actions within a millisecond", got)
+	}
+	// The window boundary is exact to the nanosecond.
+	boundary := first.Add(time.Minute)
+	if got := countWithin(a.actions, boundary, time.Minute); got != 5 {
+		t.Errorf("at the boundary: counted %d, want 5", got)
+	}
+	if got := countWithin(a.actions, boundary.Add(time.Nanosecond), time.Minute); got != 4 {
+		t.Errorf("just past the boundary: counted %d, want 4", got)
+	}
+}
+
+func TestAverageAPMPastCapacity(t *testing.T) {
+	a, clock := newTestTracker(t)
+	a.resizeActions(100)
+	// 300 actions a second apart, three times what the buffer holds.
+	for i := 0; i < 300; i++ {
+		clock.advance(time.Second)
+		a.addAction(KeyboardAction, 30)
+	}
+	if got := a.actions.Len(); got != 100 {
+		t.Fatalf("buffer holds %d actions, want 100", got)
+	}
+	if got := a.calculateAverageAPM(); got != 60 {
+		t.Errorf("average APM = %v, want 60", got)
+	}
+}
+
+func TestCurrentAPMWindowBoundary(t *testing.T) {
+	// One action a second for a minute: keys on even seconds, clicks on odd.
+	a, clock := newTestTracker(t)
+	start := clock.now()
+	for i := 0; i < 60; i++ {
+		clock.t = start.Add(time.Duration(i) * time.Second)
+		kind := KeyboardAction
+		if i%2 == 1 {
+			kind = MouseAction
+		}
+		a.onAction(kind, 30)
+	}
+	tests := []struct {
+		at                   time.Duration
+		window               time.Duration
+		current, keys, mouse int
+	}{
+		{59 * time.Second, time.Minute, 60, 30, 30},
+		{time.Minute, time.Minute, 60, 30, 30},
+		{time.Minute + time.Nanosecond, time.Minute, 59, 29, 30},
+		{119 * time.Second, time.Minute, 1, 0, 1},
+		{119*time.Second + time.Nanosecond, time.Minute, 0, 0, 0},
+		{59 * time.Second, 10 * time.Second, 66, 30, 36},
+		{69*time.Second + time.Nanosecond, 10 * time.Second, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		clock.t = start.Add(tt.at)
+		a.apmWindow = tt.window
+		if got := a.calculateCurrentAPM(); got != tt.current {
+			t.Errorf("at %s over %s: current APM = %d, want %d", tt.at, tt.window, got, tt.current)
+		}
+		if got := a.calculateKeyboardAPM(); got != tt.keys {
+			t.Errorf("at %s over %s: keyboard APM = %d, want %d", tt.at, tt.window, got, tt.keys)
+		}
+		if got := a.calculateMouseAPM(); got != tt.mouse {
+			t.Errorf("at %s over %s: mouse APM = %d, want %d", tt.at, tt.window, got, tt.mouse)
+		}
+	}
+}
+
+func TestResetClearsSessionState(t *testing.T) {
+	a, clock := newTestTracker(t)
+	// Frozen, the reset skips redrawing a window the test doesn't have.
+	a.frozen = true
+	a.cooldowns[MouseAction] = time.Second
+	a.combos = []Combo{{Keys: []string{"q", "w"}, Weight: 2}}
+	a.comboWeighting = true
+	a.alertEnabled, a.alertThreshold = true, 60
+
+	a.onAction(MouseAction, 1)
+	a.matchCombo(hook.Keycode["q"])
+	a.checkLowAPM(a.updateStats())
+	clock.advance(100 * time.Millisecond)
+	a.reset()
+
+	if !a.onAction(MouseAction, 1) {
+		t.Error("the first click after a reset fell in the old cooldown")
+	}
+	a.matchCombo(hook.Keycode["w"])
+	if n := a.bonuses.Len(); n != 0 {
+		t.Errorf("a combo started before the reset completed after it (%d bonuses)", n)
+	}
+	a.mutex.Lock()
+	lowSince := a.lowSince
+	a.mutex.Unlock()
+	if !lowSince.IsZero() {
+		t.Error("the low APM alert kept timing from before the reset")
+	}
+}
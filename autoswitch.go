@@ -0,0 +1,81 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const defaultAutoSwitchInterval = 2 * time.Second
+
+var autoSwitchIntervals = []time.Duration{
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+func (a *APMTracker) getAutoSwitch() (enabled bool, interval time.Duration) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.autoSwitch, a.autoInterval
+}
+
+func (a *APMTracker) setAutoSwitch(enabled bool, interval time.Duration) {
+	a.mutex.Lock()
+	a.autoSwitch = enabled
+	a.autoInterval = interval
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+func (a *APMTracker) getProfileExe() string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.profileExe
+}
+
+func (a *APMTracker) setProfileExe(pattern string) {
+	a.mutex.Lock()
+	a.profileExe = strings.TrimSpace(pattern)
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+// matchProfile returns the first profile whose executable pattern matches
+// the process name, compared case-insensitively as a glob.
+func matchProfile(process string) (string, bool) {
+	process = strings.ToLower(process)
+	for _, name := range listProfiles() {
+		p := readProfile(name)
+		if p.Executable == "" {
+			continue
+		}
+		if ok, _ := filepath.Match(strings.ToLower(p.Executable), process); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// watchForeground polls the foreground process and switches to the matching
+// profile. Switching only swaps settings, so it never records an action.
+func (a *APMTracker) watchForeground() {
+	var last string
+	for a.isRunning() {
+		enabled, interval := a.getAutoSwitch()
+		time.Sleep(interval)
+		if !enabled {
+			last = ""
+			continue
+		}
+		process, ok := foregroundProcess()
+		if !ok || process == last {
+			continue
+		}
+		last = process
+		if name, ok := matchProfile(process); ok && name != a.getProfile() {
+			a.switchProfile(name)
+		}
+	}
+}
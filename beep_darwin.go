@@ -0,0 +1,18 @@
+//go:build darwin
+
+package main
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Cocoa
+#import <Cocoa/Cocoa.h>
+
+static void apm_beep(void) {
+	NSBeep();
+}
+*/
+import "C"
+
+func beep() {
+	C.apm_beep()
+}
@@ -0,0 +1,17 @@
+//go:build !windows && !darwin
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// beep prefers the desktop sound theme and falls back to the terminal bell.
+func beep() {
+	if err := exec.Command("canberra-gtk-play", "--id=bell").Run(); err == nil {
+		return
+	}
+	fmt.Fprint(os.Stdout, "\a")
+}
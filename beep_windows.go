@@ -0,0 +1,10 @@
+//go:build windows
+
+package main
+
+var procMessageBeep = user32.NewProc("MessageBeep")
+
+func beep() {
+	const mbIconExclamation = 0x30
+	procMessageBeep.Call(mbIconExclamation)
+}
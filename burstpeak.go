@@ -0,0 +1,62 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+// peakHint explains the two peak definitions where the choice is made.
+const peakHint = "Sampled takes the highest APM shown on any update tick. " +
+	"Burst scans every action for the busiest APM window, so short bursts " +
+	"between ticks or inside a decaying average still count."
+
+func (a *APMTracker) isBurstPeak() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.burstMode
+}
+
+func (a *APMTracker) setBurstPeak(on bool) {
+	a.mutex.Lock()
+	a.burstMode = on
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.refreshLabels()
+}
+
+// updateBurstPeak raises the burst peak with windows ending at actions since
+// the last scan. Each such window reaches back at most one window width, so
+// only that much history is revisited rather than the whole buffer.
+func (a *APMTracker) updateBurstPeak(now time.Time, window time.Duration) (int, time.Time) {
+	a.mutex.Lock()
+	since := a.burstScanned
+	a.mutex.Unlock()
+
+	width := int64(window)
+	var times []int64
+	a.actions.ForEachNewest(func(t int64) bool {
+		if t <= since-width {
+			return false
+		}
+		times = append(times, t)
+		return true
+	})
+	slices.Reverse(times)
+	best, bestAt, lo := 0, int64(0), 0
+	for hi, t := range times {
+		for times[lo] <= t-width {
+			lo++
+		}
+		if t > since && hi-lo+1 > best {
+			best, bestAt = hi-lo+1, t
+		}
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if apm := perMinute(best, window); apm > a.burstPeak {
+		a.burstPeak, a.burstPeakAt = apm, time.Unix(0, bestAt)
+	}
+	a.burstScanned = now.UnixNano()
+	return a.burstPeak, a.burstPeakAt
+}
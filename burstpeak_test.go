@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeakDefinitionsDiverge(t *testing.T) {
+	tests := []struct {
+		name      string
+		window    time.Duration
+		halfLife  time.Duration // zero for the hard window
+		tick      time.Duration
+		wantBurst int
+	}{
+		{"burst between ticks", 10 * time.Second, 0, 15 * time.Second, 120},
+		{"burst inside a decaying average", 5 * time.Second, 5 * time.Second, time.Second, 240},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, clock := newTestTracker(t)
+			a.apmWindow = tt.window
+			a.decayMode, a.halfLife = tt.halfLife > 0, tt.halfLife
+
+			// 20 actions over two seconds, starting a second after a tick.
+			step := 100 * time.Millisecond
+			for elapsed := time.Duration(0); elapsed < 30*time.Second; elapsed += step {
+				if elapsed > time.Second && elapsed <= 3*time.Second {
+					a.addAction(KeyboardAction, 30)
+				}
+				if elapsed%tt.tick == 0 {
+					a.updateStats()
+				}
+				clock.advance(step)
+			}
+
+			a.burstMode = false
+			sampled := a.updateStats().Peak
+			a.burstMode = true
+			burst := a.updateStats().Peak
+			if burst != tt.wantBurst {
+				t.Errorf("burst peak = %d, want %d", burst, tt.wantBurst)
+			}
+			if sampled >= burst {
+				t.Errorf("sampled peak = %d, want below the burst peak %d", sampled, burst)
+			}
+		})
+	}
+}
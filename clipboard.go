@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// formatStatsText renders a snapshot for pasting into chat, either as plain
+// lines or as a Markdown table.
+func formatStatsText(stats Stats, markdown bool) string {
+	rows := [][2]string{
+		{"Current APM", fmt.Sprint(stats.Current)},
+		{"Peak APM", fmt.Sprint(stats.Peak)},
+		{"Average APM", fmt.Sprintf("%.2f", stats.Average)},
+		{"Total actions", fmt.Sprint(stats.TotalActions)},
+		{"Session", formatClock(stats.Elapsed)},
+	}
+	var b strings.Builder
+	if markdown {
+		b.WriteString("| Stat | Value |\n|---|---|\n")
+		for _, row := range rows {
+			fmt.Fprintf(&b, "| %s | %s |\n", row[0], row[1])
+		}
+	} else {
+		for _, row := range rows {
+			fmt.Fprintf(&b, "%s: %s\n", row[0], row[1])
+		}
+	}
+	return b.String()
+}
+
+func (a *APMTracker) isCopyMarkdown() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.copyMarkdown
+}
+
+func (a *APMTracker) setCopyMarkdown(on bool) {
+	a.mutex.Lock()
+	a.copyMarkdown = on
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+// copyStats copies the snapshot shown on the last tick, which was taken in
+// one piece, so the values agree with each other and with the window.
+func (a *APMTracker) copyStats() {
+	a.window.Clipboard().SetContent(formatStatsText(a.latestStats(), a.isCopyMarkdown()))
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"github.com/robotn/gohook"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// comboWindow is how quickly a combo's keys must follow one another, from
+// the first key to the last.
+const comboWindow = time.Second
+
+// Combo weights a key sequence: completing it within comboWindow counts each
+// of its keys Weight times towards weighted APM.
+type Combo struct {
+	Keys   []string `json:"keys"`
+	Weight float64  `json:"weight"`
+}
+
+type keyPress struct {
+	code uint16
+	at   int64
+}
+
+type comboBonus struct {
+	at    int64
+	extra float64
+}
+
+func comboCodes(combo Combo) ([]uint16, error) {
+	if len(combo.Keys) == 0 {
+		return nil, fmt.Errorf("empty combo")
+	}
+	codes := make([]uint16, len(combo.Keys))
+	for i, name := range combo.Keys {
+		code, ok := hook.Keycode[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", name)
+		}
+		codes[i] = code
+	}
+	return codes, nil
+}
+
+// parseCombos reads one combo per line as "keys = weight", e.g. "q w e = 2".
+func parseCombos(text string) ([]Combo, error) {
+	var combos []Combo
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		keys, weight, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"keys = weight\"", i+1)
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+		if err != nil || w <= 0 {
+			return nil, fmt.Errorf("line %d: invalid weight %q", i+1, strings.TrimSpace(weight))
+		}
+		combo := Combo{Keys: strings.Fields(keys), Weight: w}
+		if _, err := comboCodes(combo); err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		combos = append(combos, combo)
+	}
+	return combos, nil
+}
+
+func formatCombos(combos []Combo) string {
+	lines := make([]string, len(combos))
+	for i, combo := range combos {
+		lines[i] = fmt.Sprintf("%s = %g", strings.Join(combo.Keys, " "), combo.Weight)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (a *APMTracker) getCombos() (enabled bool, combos []Combo) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.comboWeighting, a.combos
+}
+
+func (a *APMTracker) setCombos(enabled bool, combos []Combo) {
+	a.mutex.Lock()
+	a.comboWeighting = enabled
+	a.combos = combos
+	a.mutex.Unlock()
+	a.saveConfig()
+	if a.weightedLabel != nil {
+		if enabled {
+			a.weightedLabel.Show()
+		} else {
+			a.weightedLabel.Hide()
+		}
+	}
+}
+
+// matchCombo checks whether a counted key press completes a combo. recentKeys
+// is locked because a reset clears it from outside the input loop.
+func (a *APMTracker) matchCombo(code uint16) {
+	enabled, combos := a.getCombos()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if !enabled || len(combos) == 0 {
+		a.recentKeys = a.recentKeys[:0]
+		return
+	}
+	at := a.now().UnixNano()
+	a.recentKeys = append(a.recentKeys, keyPress{code, at})
+	longest := 0
+	for _, combo := range combos {
+		longest = max(longest, len(combo.Keys))
+	}
+	if len(a.recentKeys) > longest {
+		a.recentKeys = a.recentKeys[len(a.recentKeys)-longest:]
+	}
+
+	for _, combo := range combos {
+		codes, err := comboCodes(combo)
+		n := len(codes)
+		if err != nil || n > len(a.recentKeys) {
+			continue
+		}
+		recent := a.recentKeys[len(a.recentKeys)-n:]
+		if at-recent[0].at > int64(comboWindow) {
+			continue
+		}
+		matched := true
+		for i, c := range codes {
+			if recent[i].code != c {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			a.bonuses.Append(comboBonus{at: at, extra: (combo.Weight - 1) * float64(n)})
+			// Start afresh so one key can't complete overlapping combos.
+			a.recentKeys = a.recentKeys[:0]
+			return
+		}
+	}
+}
+
+// calculateWeightedAPM is the current APM plus the extra weight of combos
+// completed within the same window.
+func (a *APMTracker) calculateWeightedAPM() int {
+	now, window := a.now(), a.getAPMWindow()
+	windowStart := now.Add(-window).UnixNano()
+	extra := 0.0
+	a.bonuses.ForEachNewest(func(b comboBonus) bool {
+		if b.at < windowStart {
+			return false
+		}
+		extra += b.extra
+		return true
+	})
+	count := float64(countWithin(a.actions, now, window)) + extra
+	return int(count * float64(time.Minute) / float64(window))
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/robotn/gohook"
+	"testing"
+	"time"
+)
+
+func TestComboWeighting(t *testing.T) {
+	tap := func(names ...string) []hook.Event {
+		var events []hook.Event
+		for _, name := range names {
+			events = append(events, press(name), release(name))
+		}
+		return events
+	}
+	tests := []struct {
+		name  string
+		keys  [][]string // each group is typed at once, a gap apart
+		gap   time.Duration
+		wantN int
+		want  int
+	}{
+		{"combo completed", [][]string{{"q", "w", "e"}}, 0, 3, 6},
+		{"wrong order", [][]string{{"w", "q", "e"}}, 0, 3, 3},
+		{"too slow", [][]string{{"q", "w"}, {"e"}}, 2 * time.Second, 3, 3},
+		{"within the window", [][]string{{"q", "w"}, {"e"}}, 500 * time.Millisecond, 3, 6},
+		{"one key can't finish two combos", [][]string{{"q", "w", "e", "w", "e"}}, 0, 5, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, clock := newTestTracker(t)
+			a.comboWeighting = true
+			a.combos = []Combo{{Keys: []string{"q", "w", "e"}, Weight: 2}}
+			for i, group := range tt.keys {
+				if i > 0 {
+					clock.advance(tt.gap)
+				}
+				feedEvents(a, tap(group...)...)
+			}
+			if got := int(a.getTotalActions()); got != tt.wantN {
+				t.Fatalf("total actions = %d, want %d", got, tt.wantN)
+			}
+			if got := a.calculateWeightedAPM(); got != tt.want {
+				t.Errorf("weighted APM = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
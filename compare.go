@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"image"
+	"sort"
+	"time"
+)
+
+func (a *APMTracker) getComparison() *Session {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.compareTo
+}
+
+func (a *APMTracker) setComparison(s *Session) {
+	a.mutex.Lock()
+	a.compareTo = s
+	a.mutex.Unlock()
+	a.updateGraph()
+}
+
+// compareLastSession compares against the saved session, which still holds
+// the previous run until this one exits.
+func (a *APMTracker) compareLastSession() {
+	path, err := sessionPath()
+	if err == nil {
+		var s *Session
+		if s, err = loadSession(path); err == nil {
+			a.setComparison(s)
+			return
+		}
+	}
+	dialog.ShowError(err, a.window)
+}
+
+func (a *APMTracker) showCompareDialog() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		reader.Close()
+		s, err := loadSession(reader.URI().Path())
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		a.setComparison(s)
+	}, a.window)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	open.Show()
+}
+
+// compareBuckets buckets s at the same elapsed time into its run as now is
+// into this one, so two sessions line up from their starts. Buckets beyond
+// either end of s are -1.
+func (a *APMTracker) compareBuckets(s *Session, now time.Time, span time.Duration, n int) []int {
+	a.mutex.Lock()
+	elapsed := now.Sub(a.startTime)
+	a.mutex.Unlock()
+	end := s.StartTime.Add(elapsed).UnixNano()
+	bucket := int64(span) / int64(n)
+	buckets := make([]int, n)
+	for i := range buckets {
+		bucketEnd := end - int64(i)*bucket
+		if bucketEnd-bucket < s.StartTime.UnixNano() || bucketEnd-bucket > s.EndTime.UnixNano() {
+			buckets[i] = -1
+		}
+	}
+	// Timestamps are saved oldest first.
+	ts := s.Timestamps
+	first := sort.Search(len(ts), func(i int) bool { return ts[i] > end-int64(span) })
+	for _, t := range ts[first:] {
+		if t > end {
+			break
+		}
+		if i := (end - t) / bucket; i < int64(n) && buckets[i] >= 0 {
+			buckets[i]++
+		}
+	}
+	return buckets
+}
+
+// compareLine draws the comparison session underneath the live data, broken
+// where it has no data.
+func (r GraphRenderer) compareLine(img *image.RGBA, buckets []int, scale float64) {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	prevX, prevY, prevOK := 0, 0, false
+	for i, count := range buckets {
+		if count < 0 {
+			prevOK = false
+			continue
+		}
+		barHeight := min(int(float64(count)/scale*float64(height-1)), height-1)
+		x := width - (i+1)*r.stride() + r.BarWidth/2
+		y := height - 1 - barHeight
+		if prevOK {
+			drawLine(img, prevX, prevY, x, y, r.Palette.Compare)
+		}
+		prevX, prevY, prevOK = x, y, true
+	}
+}
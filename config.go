@@ -0,0 +1,457 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"io/fs"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+type Config struct {
+	Profile   string `json:"profile,omitempty"`
+	Theme     string `json:"theme"`
+	BarColor  string `json:"bar_color,omitempty"`
+	BarWidth  int    `json:"bar_width,omitempty"`
+	TargetAPM int    `json:"target_apm,omitempty"`
+
+	AlertEnabled   bool `json:"alert_enabled"`
+	AlertThreshold int  `json:"alert_threshold,omitempty"`
+	AlertSeconds   int  `json:"alert_seconds,omitempty"`
+
+	Hotkey string `json:"toggle_hotkey,omitempty"`
+
+	MiniMonitor string  `json:"mini_monitor,omitempty"`
+	MiniCorner  string  `json:"mini_corner,omitempty"`
+	MiniCustom  bool    `json:"mini_custom_position,omitempty"`
+	MiniX       int     `json:"mini_x,omitempty"`
+	MiniY       int     `json:"mini_y,omitempty"`
+	MiniOpacity float64 `json:"mini_opacity,omitempty"`
+
+	OverlayFontSize     float32 `json:"overlay_font_size,omitempty"`
+	OverlayTransparency float64 `json:"overlay_transparency,omitempty"`
+
+	CountEvents    map[string]bool `json:"count_events,omitempty"`
+	CountModifiers bool            `json:"count_modifiers,omitempty"`
+	IgnoreRepeat   bool            `json:"ignore_repeat,omitempty"`
+
+	AutoSwitch     bool `json:"auto_switch_profiles,omitempty"`
+	AutoSwitchSecs int  `json:"auto_switch_seconds,omitempty"`
+
+	SessionMinutes int     `json:"session_minutes,omitempty"`
+	ResumeMins     *int    `json:"resume_minutes,omitempty"`
+	EMAAlpha       float64 `json:"ema_alpha,omitempty"`
+	ActionCapacity int     `json:"action_capacity,omitempty"`
+	RecentPeakMins int     `json:"recent_peak_minutes,omitempty"`
+	RecentAvgMins  int     `json:"recent_average_minutes,omitempty"`
+	SampleHours    int     `json:"sample_retention_hours,omitempty"`
+	CountFocused   bool    `json:"count_while_focused,omitempty"`
+	GraphRangeSecs int     `json:"graph_range_seconds,omitempty"`
+	StackedGraph   bool    `json:"stacked_graph,omitempty"`
+	GraphMaxAPM    int     `json:"graph_max_apm,omitempty"`
+	PeakNotify     bool    `json:"peak_notifications,omitempty"`
+	CountIdle      bool    `json:"count_idle_time,omitempty"`
+	IdleSeconds    int     `json:"idle_timeout_seconds,omitempty"`
+	WarmUpSeconds  int     `json:"min_apm_warm_up_seconds,omitempty"`
+	MiniSparkline  bool    `json:"mini_sparkline,omitempty"`
+	CountGamepad   bool    `json:"count_gamepad,omitempty"`
+	GraphFPS       float64 `json:"graph_fps,omitempty"`
+	UpdateMs       int     `json:"update_interval_ms,omitempty"`
+	LineGraph      bool    `json:"line_graph,omitempty"`
+	SmoothAPS      bool    `json:"smooth_aps,omitempty"`
+	HistogramWidth int     `json:"histogram_bucket_width,omitempty"`
+	CopyMarkdown   bool    `json:"copy_markdown,omitempty"`
+	DecayAPM       bool    `json:"decay_apm,omitempty"`
+	HalfLifeSecs   int     `json:"decay_half_life_seconds,omitempty"`
+	AvgDecimals    *int    `json:"average_decimals,omitempty"`
+	ThousandsSep   string  `json:"thousands_separator,omitempty"`
+	CompactLayout  bool    `json:"compact_layout,omitempty"`
+	StartMini      bool    `json:"start_in_mini_view,omitempty"`
+	MiniOnTop      *bool   `json:"mini_on_top,omitempty"`
+	RememberView   bool    `json:"remember_view,omitempty"`
+	RecordNotify   bool    `json:"record_notifications,omitempty"`
+	GraphDots      bool    `json:"graph_markers,omitempty"`
+	CurrentLine    bool    `json:"graph_current_line,omitempty"`
+	MovingAvg      bool    `json:"graph_moving_average,omitempty"`
+	MAWindowSecs   int     `json:"moving_average_seconds,omitempty"`
+	CloseToTray    bool    `json:"close_to_tray,omitempty"`
+	ClickThrough   bool    `json:"click_through,omitempty"`
+	SpamMs         int     `json:"spam_threshold_ms,omitempty"`
+	WallClock      bool    `json:"graph_wall_clock,omitempty"`
+	BurstPeak      bool    `json:"burst_peak,omitempty"`
+
+	ComboWeighting bool    `json:"combo_weighting,omitempty"`
+	Combos         []Combo `json:"combos,omitempty"`
+
+	CooldownMs map[string]int `json:"cooldown_ms,omitempty"`
+
+	Zones   bool   `json:"apm_zones,omitempty"`
+	ZoneSet []Zone `json:"zones,omitempty"`
+
+	KeyRegions bool        `json:"key_regions,omitempty"`
+	RegionSet  []KeyRegion `json:"regions,omitempty"`
+}
+
+// dataDir, when set by -data-dir, replaces the OS config directory.
+var dataDir string
+
+// configDir is where every file the app keeps lives: the OS convention
+// (XDG_CONFIG_HOME or ~/.config, Application Support, AppData) unless
+// overridden. It is created on first use.
+func configDir() (string, error) {
+	dir := dataDir
+	if dir == "" {
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(base, "apmgo")
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return dir, os.MkdirAll(dir, 0o755)
+}
+
+func configPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+func loadConfig(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	err = json.Unmarshal(data, &cfg)
+	return cfg, err
+}
+
+func saveConfig(path string, cfg Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (a *APMTracker) config() Config {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	decimals := a.avgDecimals
+	onTop := a.miniOnTop
+	resumeMins := int(a.resumeWindow.Minutes())
+	cooldownMs := make(map[string]int)
+	for _, t := range actionTypes {
+		if d := a.cooldowns[t.Kind]; d > 0 {
+			cooldownMs[t.Name] = int(d.Milliseconds())
+		}
+	}
+	return Config{
+		Profile:   a.profile,
+		Theme:     a.themeName,
+		BarColor:  formatHexColor(a.barColor),
+		BarWidth:  a.barWidth,
+		TargetAPM: a.targetAPM,
+
+		AlertEnabled:   a.alertEnabled,
+		AlertThreshold: a.alertThreshold,
+		AlertSeconds:   int(a.alertDuration.Seconds()),
+
+		Hotkey: a.hotkey.String(),
+
+		MiniMonitor: a.miniMonitor,
+		MiniCorner:  a.miniCorner,
+		MiniCustom:  a.miniCustomPos,
+		MiniX:       a.miniPos.X,
+		MiniY:       a.miniPos.Y,
+		MiniOpacity: a.miniOpacity,
+		CountEvents: maps.Clone(a.countEvents),
+
+		OverlayFontSize:     a.overlaySize,
+		OverlayTransparency: 1 - a.overlayAlpha,
+
+		CountModifiers: !a.excludeMods,
+		IgnoreRepeat:   a.ignoreRepeat,
+
+		AutoSwitch:     a.autoSwitch,
+		AutoSwitchSecs: int(a.autoInterval.Seconds()),
+
+		SessionMinutes: int(a.sessionLength.Minutes()),
+		ResumeMins:     &resumeMins,
+		EMAAlpha:       a.emaAlpha,
+		ActionCapacity: a.actionCap,
+		RecentPeakMins: int(a.recentWindow.Minutes()),
+		RecentAvgMins:  int(a.recentAvgWin.Minutes()),
+		SampleHours:    int(a.sampleRetain.Hours()),
+		CountFocused:   !a.ignoreFocused,
+		GraphRangeSecs: int(a.graphRange.Seconds()),
+		StackedGraph:   a.stackedGraph,
+		GraphMaxAPM:    a.graphMax,
+		PeakNotify:     a.peakNotify,
+		CountIdle:      !a.idlePause,
+		IdleSeconds:    int(a.idleTimeout.Seconds()),
+		WarmUpSeconds:  int(a.warmUp.Seconds()),
+		MiniSparkline:  a.sparkline,
+		CountGamepad:   a.countGamepad,
+		GraphFPS:       float64(time.Second) / float64(a.graphInterval),
+		UpdateMs:       int(a.updateInterval.Milliseconds()),
+		LineGraph:      a.graphMode == LineGraph,
+		SmoothAPS:      a.smoothAPS,
+		HistogramWidth: a.histogramWidth,
+		CopyMarkdown:   a.copyMarkdown,
+		DecayAPM:       a.decayMode,
+		HalfLifeSecs:   int(a.halfLife.Seconds()),
+		AvgDecimals:    &decimals,
+		ThousandsSep:   a.thousandsSep,
+		CompactLayout:  a.compact,
+		StartMini:      a.startMini,
+		MiniOnTop:      &onTop,
+		RememberView:   a.rememberView,
+		RecordNotify:   a.recordNotify,
+		GraphDots:      a.graphDots,
+		CurrentLine:    a.currentLine,
+		MovingAvg:      a.maLine,
+		MAWindowSecs:   int(a.maWindow.Seconds()),
+		CloseToTray:    a.closeToTray,
+		ClickThrough:   a.clickThrough,
+		SpamMs:         int(a.spamThreshold.Milliseconds()),
+		WallClock:      a.wallClock,
+		BurstPeak:      a.burstMode,
+
+		ComboWeighting: a.comboWeighting,
+		Combos:         a.combos,
+
+		CooldownMs: cooldownMs,
+
+		Zones:   a.zonesOn,
+		ZoneSet: a.zones,
+
+		KeyRegions: a.regionsOn,
+		RegionSet:  a.keyRegions,
+	}
+}
+
+func (a *APMTracker) applyConfig(cfg Config) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if cfg.Theme == darkTheme {
+		a.themeName = darkTheme
+		a.palette = darkPalette
+	}
+	if cfg.BarColor != "" {
+		if c, err := parseHexColor(cfg.BarColor); err == nil {
+			a.barColor = c
+		} else {
+			log.Printf("warning: %v", err)
+		}
+	}
+	if cfg.BarWidth > 0 {
+		a.barWidth = min(cfg.BarWidth, maxBarWidth)
+	}
+	a.targetAPM = max(cfg.TargetAPM, 0)
+	a.alertEnabled = cfg.AlertEnabled
+	if cfg.AlertThreshold > 0 {
+		a.alertThreshold = cfg.AlertThreshold
+	}
+	if cfg.AlertSeconds > 0 {
+		a.alertDuration = time.Duration(cfg.AlertSeconds) * time.Second
+	}
+	if cfg.Hotkey != "" {
+		if err := a.hotkey.Set(cfg.Hotkey); err != nil {
+			log.Printf("warning: ignoring hotkey: %v", err)
+		}
+	}
+	a.miniMonitor = cfg.MiniMonitor
+	if cfg.MiniCorner != "" {
+		a.miniCorner = cfg.MiniCorner
+	}
+	a.miniCustomPos = cfg.MiniCustom
+	a.miniPos = image.Pt(cfg.MiniX, cfg.MiniY)
+	if cfg.MiniOpacity > 0 {
+		a.miniOpacity = min(max(cfg.MiniOpacity, minMiniOpacity), maxMiniOpacity)
+	}
+	if cfg.OverlayFontSize > 0 {
+		a.overlaySize = min(max(cfg.OverlayFontSize, minOverlaySize), maxOverlaySize)
+	}
+	a.overlayAlpha = 1 - min(max(cfg.OverlayTransparency, 0), 1)
+	for name, on := range cfg.CountEvents {
+		if on {
+			a.countEvents[name] = true
+		}
+	}
+	a.excludeMods = !cfg.CountModifiers
+	a.ignoreRepeat = cfg.IgnoreRepeat
+	if cfg.Profile != "" {
+		a.profile = cfg.Profile
+	}
+	a.autoSwitch = cfg.AutoSwitch
+	if cfg.AutoSwitchSecs > 0 {
+		a.autoInterval = time.Duration(cfg.AutoSwitchSecs) * time.Second
+	}
+	a.sessionLength = time.Duration(max(cfg.SessionMinutes, 0)) * time.Minute
+	if cfg.ResumeMins != nil {
+		a.resumeWindow = time.Duration(max(*cfg.ResumeMins, 0)) * time.Minute
+	}
+	if cfg.EMAAlpha > 0 {
+		a.emaAlpha = min(cfg.EMAAlpha, 1)
+	}
+	if cfg.RecentPeakMins > 0 {
+		a.recentWindow = time.Duration(cfg.RecentPeakMins) * time.Minute
+	}
+	if cfg.RecentAvgMins > 0 {
+		a.recentAvgWin = time.Duration(cfg.RecentAvgMins) * time.Minute
+	}
+	if cfg.SampleHours > 0 {
+		a.sampleRetain = time.Duration(cfg.SampleHours) * time.Hour
+	}
+	a.ignoreFocused = !cfg.CountFocused
+	if cfg.GraphRangeSecs > 0 {
+		a.graphRange = time.Duration(cfg.GraphRangeSecs) * time.Second
+	}
+	a.stackedGraph = cfg.StackedGraph
+	a.graphMax = max(cfg.GraphMaxAPM, 0)
+	a.peakNotify = cfg.PeakNotify
+	a.idlePause = !cfg.CountIdle
+	a.sparkline = cfg.MiniSparkline
+	a.countGamepad = cfg.CountGamepad
+	a.copyMarkdown = cfg.CopyMarkdown
+	a.decayMode = cfg.DecayAPM
+	if cfg.HalfLifeSecs > 0 {
+		a.halfLife = time.Duration(cfg.HalfLifeSecs) * time.Second
+	}
+	if cfg.AvgDecimals != nil {
+		a.avgDecimals = min(max(*cfg.AvgDecimals, 0), 3)
+	}
+	if slices.Contains(thousandsSeps, cfg.ThousandsSep) {
+		a.thousandsSep = cfg.ThousandsSep
+	}
+	a.compact = cfg.CompactLayout
+	a.startMini = cfg.StartMini
+	if cfg.MiniOnTop != nil {
+		a.miniOnTop = *cfg.MiniOnTop
+	}
+	a.rememberView = cfg.RememberView
+	a.recordNotify = cfg.RecordNotify
+	a.graphDots = cfg.GraphDots
+	a.currentLine = cfg.CurrentLine
+	a.maLine = cfg.MovingAvg
+	a.closeToTray = cfg.CloseToTray
+	if cfg.SpamMs > 0 {
+		a.spamThreshold = time.Duration(cfg.SpamMs) * time.Millisecond
+	}
+	a.wallClock = cfg.WallClock
+	a.burstMode = cfg.BurstPeak
+	a.zonesOn = cfg.Zones
+	if len(cfg.ZoneSet) > 0 {
+		if zones, err := parseZones(formatZones(cfg.ZoneSet)); err == nil {
+			a.zones = zones
+		} else {
+			log.Printf("warning: ignoring zones: %v", err)
+		}
+	}
+	a.regionsOn = cfg.KeyRegions
+	if len(cfg.RegionSet) > 0 {
+		if regions, err := parseRegions(formatRegions(cfg.RegionSet)); err == nil {
+			a.keyRegions = regions
+			a.regionOf = regionIndex(regions)
+		} else {
+			log.Printf("warning: ignoring key regions: %v", err)
+		}
+	}
+	a.clickThrough = cfg.ClickThrough && clickThroughSupported
+	for _, t := range actionTypes {
+		a.cooldowns[t.Kind] = time.Duration(max(cfg.CooldownMs[t.Name], 0)) * time.Millisecond
+	}
+	if cfg.MAWindowSecs > 0 {
+		a.maWindow = time.Duration(cfg.MAWindowSecs) * time.Second
+	}
+	if cfg.GraphFPS > 0 {
+		a.graphInterval = time.Duration(float64(time.Second) / min(cfg.GraphFPS, 30))
+	}
+	if cfg.UpdateMs > 0 {
+		d := time.Duration(cfg.UpdateMs) * time.Millisecond
+		a.updateInterval = min(max(d, updateIntervals[0]), updateIntervals[len(updateIntervals)-1])
+	}
+	a.graphMode = BarGraph
+	if cfg.LineGraph {
+		a.graphMode = LineGraph
+	}
+	a.smoothAPS = cfg.SmoothAPS
+	if slices.Contains(histogramWidths, cfg.HistogramWidth) {
+		a.histogramWidth = cfg.HistogramWidth
+	}
+	if cfg.WarmUpSeconds > 0 {
+		a.warmUp = time.Duration(cfg.WarmUpSeconds) * time.Second
+	}
+	if cfg.IdleSeconds > 0 {
+		a.idleTimeout = time.Duration(cfg.IdleSeconds) * time.Second
+	}
+	a.comboWeighting = cfg.ComboWeighting
+	a.combos = nil
+	for _, combo := range cfg.Combos {
+		if _, err := comboCodes(combo); err != nil {
+			log.Printf("warning: ignoring combo %v: %v", combo.Keys, err)
+			continue
+		}
+		if combo.Weight <= 0 {
+			log.Printf("warning: ignoring combo %v: invalid weight %v", combo.Keys, combo.Weight)
+			continue
+		}
+		a.combos = append(a.combos, combo)
+	}
+}
+
+func (a *APMTracker) loadConfig() {
+	path, err := configPath()
+	if err != nil {
+		log.Printf("warning: failed to locate config file: %v", err)
+		return
+	}
+	cfg, err := loadConfig(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		log.Printf("warning: ignoring unreadable config %s: %v", path, err)
+		return
+	}
+	a.applyConfig(cfg)
+	if cfg.ActionCapacity > 0 {
+		a.resizeActions(cfg.ActionCapacity)
+	}
+	a.resizeSamples()
+	if cfg.Profile != "" {
+		a.applyProfile(readProfile(cfg.Profile))
+	}
+}
+
+// saveConfig persists the config along with the active profile, so settings
+// changed while a profile is selected stick to that profile.
+func (a *APMTracker) saveConfig() {
+	a.writeConfig()
+	a.saveProfile()
+}
+
+func (a *APMTracker) writeConfig() {
+	path, err := configPath()
+	if err == nil {
+		err = saveConfig(path, a.config())
+	}
+	if err != nil {
+		log.Printf("failed to save config: %v", err)
+	}
+}
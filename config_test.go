@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDisplaySettingsPersist(t *testing.T) {
+	a, _ := newTestTracker(t)
+	a.setUpdateInterval(250 * time.Millisecond)
+	a.setSmoothAPS(true)
+	a.graphMode = LineGraph
+	a.histogramWidth = 100
+	a.saveConfig()
+
+	b := NewAPMTracker()
+	b.loadConfig()
+	if got := b.getUpdateInterval(); got != 250*time.Millisecond {
+		t.Errorf("update interval = %s, want 250ms", got)
+	}
+	if !b.isSmoothAPS() {
+		t.Error("APS smoothing not restored")
+	}
+	if got := b.getGraphMode(); got != LineGraph {
+		t.Errorf("graph mode = %d, want line", got)
+	}
+	if got := b.getHistogramWidth(); got != 100 {
+		t.Errorf("histogram width = %d, want 100", got)
+	}
+}
+
+func TestApplyConfigClamps(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		interval  time.Duration
+		histogram int
+	}{
+		{"interval too short", Config{UpdateMs: 1}, 250 * time.Millisecond, defaultHistogramWidth},
+		{"interval too long", Config{UpdateMs: 60000}, 2 * time.Second, defaultHistogramWidth},
+		{"unknown width", Config{HistogramWidth: 7}, 500 * time.Millisecond, defaultHistogramWidth},
+		{"negative width", Config{HistogramWidth: -25}, 500 * time.Millisecond, defaultHistogramWidth},
+	}
+	for _, tt := range tests {
+		a, _ := newTestTracker(t)
+		a.applyConfig(tt.cfg)
+		if got := a.getUpdateInterval(); got != tt.interval {
+			t.Errorf("%s: update interval = %s, want %s", tt.name, got, tt.interval)
+		}
+		if got := a.getHistogramWidth(); got != tt.histogram {
+			t.Errorf("%s: histogram width = %d, want %d", tt.name, got, tt.histogram)
+		}
+	}
+}
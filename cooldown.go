@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fyne.io/fyne/v2/widget"
+	"time"
+)
+
+var cooldowns = []time.Duration{
+	0,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+}
+
+// actionTypes names each action type in the config and settings.
+var actionTypes = []struct {
+	Kind  ActionType
+	Name  string
+	Label string
+}{
+	{KeyboardAction, "keyboard", "Keyboard cooldown"},
+	{MouseAction, "mouse", "Mouse cooldown"},
+	{GamepadAction, "gamepad", "Gamepad cooldown"},
+}
+
+func formatCooldown(d time.Duration) string {
+	if d == 0 {
+		return "Off"
+	}
+	return d.String()
+}
+
+func (a *APMTracker) getCooldown(kind ActionType) time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.cooldowns[kind]
+}
+
+func (a *APMTracker) setCooldown(kind ActionType, d time.Duration) {
+	a.mutex.Lock()
+	a.cooldowns[kind] = d
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+// inCooldown reports whether an action of this kind at at follows the last
+// counted one too closely, as a bouncing switch's double click does. The
+// caller must hold the mutex and count the action when this returns false.
+func (a *APMTracker) inCooldown(kind ActionType, at time.Time) bool {
+	if cd := a.cooldowns[kind]; cd > 0 && at.Sub(a.lastOfKind[kind]) < cd {
+		return true
+	}
+	a.lastOfKind[kind] = at
+	return false
+}
+
+func (a *APMTracker) cooldownSelects() []*widget.FormItem {
+	options := make([]string, len(cooldowns))
+	for i, d := range cooldowns {
+		options[i] = formatCooldown(d)
+	}
+	items := make([]*widget.FormItem, 0, len(actionTypes))
+	for _, t := range actionTypes {
+		kind := t.Kind
+		sel := widget.NewSelect(options, func(s string) {
+			for _, d := range cooldowns {
+				if formatCooldown(d) == s {
+					a.setCooldown(kind, d)
+				}
+			}
+		})
+		sel.Selected = formatCooldown(a.getCooldown(kind))
+		items = append(items, widget.NewFormItem(t.Label, sel))
+	}
+	return items
+}
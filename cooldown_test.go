@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCooldownDoubleClick(t *testing.T) {
+	tests := []struct {
+		name     string
+		cooldown time.Duration
+		gap      time.Duration
+		want     int
+	}{
+		{"off", 0, 5 * time.Millisecond, 2},
+		{"bounce within the cooldown", 50 * time.Millisecond, 5 * time.Millisecond, 1},
+		{"deliberate double click", 50 * time.Millisecond, 80 * time.Millisecond, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, clock := newTestTracker(t)
+			a.cooldowns[MouseAction] = tt.cooldown
+			a.cooldowns[KeyboardAction] = tt.cooldown
+			a.onAction(MouseAction, 1)
+			clock.advance(tt.gap)
+			a.onAction(MouseAction, 1)
+			// Other action types keep their own cooldown.
+			a.onAction(KeyboardAction, 30)
+			if got := a.mouseActions.Len(); got != tt.want {
+				t.Errorf("counted %d clicks, want %d", got, tt.want)
+			}
+			if got := a.keyActions.Len(); got != 1 {
+				t.Errorf("counted %d keys, want 1", got)
+			}
+		})
+	}
+}
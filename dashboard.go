@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+const (
+	defaultDashboardAddr = "localhost:9101"
+	dashboardBuckets     = 60
+)
+
+type dashboardStats struct {
+	Current int     `json:"current"`
+	Peak    int     `json:"peak"`
+	Avg     float64 `json:"avg"`
+	Total   int64   `json:"total"`
+	Paused  bool    `json:"paused"`
+	Idle    bool    `json:"idle"`
+	Span    float64 `json:"span"` // seconds covered by buckets
+	Buckets []int   `json:"buckets"`
+	TS      int64   `json:"ts"`
+}
+
+// lanAddr keeps the port of addr but listens on every interface.
+func lanAddr(addr string) string {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return net.JoinHostPort("", port)
+}
+
+func (a *APMTracker) startDashboard(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", a.serveDashboard)
+	mux.HandleFunc("/stats", a.serveDashboardStats)
+	a.dashboardSrv = &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		err := a.dashboardSrv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("dashboard server: %v", err)
+		}
+	}()
+}
+
+func (a *APMTracker) stopDashboard() {
+	if a.dashboardSrv == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := a.dashboardSrv.Shutdown(ctx); err != nil {
+		log.Printf("dashboard server shutdown: %v", err)
+	}
+}
+
+func (a *APMTracker) serveDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := dashboardPage.Execute(w, struct {
+		Version string
+		Poll    int64
+	}{versionString(), a.getUpdateInterval().Milliseconds()})
+	if err != nil {
+		log.Printf("dashboard: %v", err)
+	}
+}
+
+func (a *APMTracker) serveDashboardStats(w http.ResponseWriter, r *http.Request) {
+	stats := a.latestStats()
+	span := a.getGraphRange()
+	buckets := bucketActions(a.actions, a.now(), span, dashboardBuckets)
+	// bucketActions puts the newest bucket first; the page draws left to right.
+	slices.Reverse(buckets)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	err := json.NewEncoder(w).Encode(dashboardStats{
+		Current: stats.Current,
+		Peak:    stats.Peak,
+		Avg:     stats.Average,
+		Total:   stats.TotalActions,
+		Paused:  stats.Paused,
+		Idle:    stats.Idle,
+		Span:    span.Seconds(),
+		Buckets: buckets,
+		TS:      a.now().UnixMilli(),
+	})
+	if err != nil {
+		log.Printf("dashboard stats: %v", err)
+	}
+}
+
+var dashboardPage = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>APM Tracker</title>
+<style>
+body { margin: 0; padding: 1em; font-family: sans-serif; background: #1e1e1e; color: #eee; }
+.stats { display: flex; flex-wrap: wrap; gap: 1em; }
+.stat { flex: 1; min-width: 6em; }
+.stat b { display: block; font-size: 2.5em; }
+.stat span { color: #aaa; }
+canvas { width: 100%; height: 40vh; margin-top: 1em; background: #2a2a2a; }
+#state { color: #e0a040; height: 1.2em; }
+footer { color: #777; font-size: 0.8em; margin-top: 1em; }
+</style>
+</head>
+<body>
+<div class="stats">
+<div class="stat"><b id="current">-</b><span>Current APM</span></div>
+<div class="stat"><b id="peak">-</b><span>Peak APM</span></div>
+<div class="stat"><b id="avg">-</b><span>Average APM</span></div>
+<div class="stat"><b id="total">-</b><span>Actions</span></div>
+</div>
+<div id="state"></div>
+<canvas id="graph"></canvas>
+<footer>{{.Version}}</footer>
+<script>
+const graph = document.getElementById("graph");
+
+function draw(buckets, span) {
+	const ctx = graph.getContext("2d");
+	graph.width = graph.clientWidth;
+	graph.height = graph.clientHeight;
+	ctx.clearRect(0, 0, graph.width, graph.height);
+	const perMinute = 60 * buckets.length / span;
+	const top = Math.max(1, ...buckets) * perMinute;
+	const w = graph.width / buckets.length;
+	ctx.fillStyle = "#4a90d9";
+	buckets.forEach((n, i) => {
+		const h = n * perMinute / top * (graph.height - 4);
+		ctx.fillRect(i * w + 1, graph.height - h, Math.max(w - 2, 1), h);
+	});
+	ctx.fillStyle = "#aaa";
+	ctx.fillText(Math.round(top) + " APM", 4, 12);
+}
+
+async function poll() {
+	try {
+		const res = await fetch("stats", {cache: "no-store"});
+		const s = await res.json();
+		document.getElementById("current").textContent = s.current;
+		document.getElementById("peak").textContent = s.peak;
+		document.getElementById("avg").textContent = s.avg.toFixed(1);
+		document.getElementById("total").textContent = s.total;
+		document.getElementById("state").textContent = s.paused ? "Paused" : s.idle ? "Idle" : "";
+		draw(s.buckets, s.span);
+	} catch (e) {
+		document.getElementById("state").textContent = "Disconnected";
+	}
+	setTimeout(poll, {{.Poll}});
+}
+poll();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServeDashboardStats(t *testing.T) {
+	a, clock := newTestTracker(t)
+	fillActions(a, clock, 30, time.Second)
+	a.updateStats()
+
+	rec := httptest.NewRecorder()
+	a.serveDashboardStats(rec, httptest.NewRequest("GET", "/stats", nil))
+	var got dashboardStats
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.TS != clock.now().UnixMilli() {
+		t.Errorf("ts = %d, want the tracker's clock %d", got.TS, clock.now().UnixMilli())
+	}
+	if got.Total != 30 || got.Current != 30 {
+		t.Errorf("total %d, current %d; want 30 and 30", got.Total, got.Current)
+	}
+	if len(got.Buckets) != dashboardBuckets {
+		t.Errorf("%d buckets, want %d", len(got.Buckets), dashboardBuckets)
+	}
+	sum := 0
+	for _, n := range got.Buckets {
+		sum += n
+	}
+	if sum != 30 {
+		t.Errorf("buckets hold %d actions, want 30", sum)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/robotn/gohook"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	inputLogLines = 200
+	// inputLogRate caps logged events per second; the rest are counted and
+	// summarized so mouse movement can't flood the pane.
+	inputLogRate = 20
+)
+
+// inputLog keeps recent hook status changes and, while the debug pane is
+// open, the raw events behind them.
+type inputLog struct {
+	lines    *RingBuffer[string]
+	mutex    sync.Mutex
+	watching bool
+	second   time.Time
+	logged   int
+	dropped  int
+}
+
+func newInputLog() *inputLog {
+	return &inputLog{lines: NewRingBuffer[string](inputLogLines)}
+}
+
+func (l *inputLog) status(format string, args ...any) {
+	l.lines.Append(time.Now().Format("15:04:05.000") + " " + fmt.Sprintf(format, args...))
+}
+
+func (l *inputLog) event(ev hook.Event) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if !l.watching {
+		return
+	}
+	if now := time.Now().Truncate(time.Second); !now.Equal(l.second) {
+		if l.dropped > 0 {
+			l.lines.Append(fmt.Sprintf("… %d more events", l.dropped))
+		}
+		l.second, l.logged, l.dropped = now, 0, 0
+	}
+	if l.logged >= inputLogRate {
+		l.dropped++
+		return
+	}
+	l.logged++
+	line := ev.String()
+	if ev.Kind == hook.KeyHold || ev.Kind == hook.KeyUp {
+		line += " " + keyName(ev.Keycode)
+	}
+	l.lines.Append(line)
+}
+
+func (l *inputLog) watch(on bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.watching = on
+}
+
+func (l *inputLog) text() string {
+	return strings.Join(l.lines.GetAll(), "\n")
+}
+
+// showInputLog opens a pane following the input log, for diagnosing capture
+// problems such as a hook that never sees events.
+func (a *APMTracker) showInputLog() {
+	if a.logWindow != nil {
+		a.logWindow.RequestFocus()
+		return
+	}
+	text := widget.NewLabel(a.inputLog.text())
+	text.TextStyle = fyne.TextStyle{Monospace: true}
+	scroll := container.NewScroll(text)
+
+	a.logWindow = a.app.NewWindow("Input Log")
+	a.logWindow.SetContent(scroll)
+	a.logWindow.Resize(fyne.NewSize(640, 360))
+	a.inputLog.watch(true)
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	go func() {
+		for range ticker.C {
+			text.SetText(a.inputLog.text())
+			scroll.ScrollToBottom()
+		}
+	}()
+	a.logWindow.SetOnClosed(func() {
+		ticker.Stop()
+		a.inputLog.watch(false)
+		a.logWindow = nil
+	})
+	a.logWindow.Show()
+}
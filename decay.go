@@ -0,0 +1,51 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+const defaultHalfLife = 20 * time.Second
+
+var halfLives = []time.Duration{
+	5 * time.Second,
+	10 * time.Second,
+	20 * time.Second,
+	30 * time.Second,
+}
+
+func (a *APMTracker) getDecayMode() (on bool, halfLife time.Duration) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.decayMode, a.halfLife
+}
+
+func (a *APMTracker) setDecayMode(on bool, halfLife time.Duration) {
+	a.mutex.Lock()
+	a.decayMode = on
+	if halfLife > 0 {
+		a.halfLife = halfLife
+	}
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.refresh()
+}
+
+// calculateDecayAPM weighs each action by how recent it is, halving every
+// halfLife, so APM glides rather than jumping as actions leave a hard window.
+// The weights of a steady stream sum to rate*halfLife/ln2, which scales the
+// total back to actions per minute.
+func (a *APMTracker) calculateDecayAPM(halfLife time.Duration) int {
+	now := a.now().UnixNano()
+	// Older actions weigh under 0.1% and are not worth visiting.
+	oldest := now - 10*int64(halfLife)
+	sum := 0.0
+	a.actions.ForEachNewest(func(t int64) bool {
+		if t < oldest {
+			return false
+		}
+		sum += math.Exp2(-float64(now-t) / float64(halfLife))
+		return true
+	})
+	return int(sum * math.Ln2 * float64(time.Minute) / float64(halfLife))
+}
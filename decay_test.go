@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecayAgainstHardWindow(t *testing.T) {
+	// One action a second for five minutes, then nothing.
+	a, clock := newTestTracker(t)
+	a.apmWindow, a.halfLife = time.Minute, 20*time.Second
+	for i := 0; i < 300; i++ {
+		clock.advance(time.Second)
+		a.addAction(KeyboardAction, 30)
+	}
+	stop := clock.now()
+
+	// Decay should track the hard window on a steady stream, then glide down
+	// by half every half-life where the hard window drops off a cliff. The
+	// window includes its start, so a steady minute holds 61 actions.
+	tests := []struct {
+		after       time.Duration
+		hard, decay int
+	}{
+		{0, 61, 60},
+		{20 * time.Second, 41, 30},
+		{40 * time.Second, 21, 15},
+		{time.Minute, 1, 7},
+		{61 * time.Second, 0, 7},
+	}
+	for _, tt := range tests {
+		clock.t = stop.Add(tt.after)
+		a.decayMode = false
+		if got := a.calculateCurrentAPM(); got != tt.hard {
+			t.Errorf("%s after: hard window APM = %d, want %d", tt.after, got, tt.hard)
+		}
+		a.decayMode = true
+		if got := a.calculateCurrentAPM(); got < tt.decay || got > tt.decay+1 {
+			t.Errorf("%s after: decay APM = %d, want %d or %d", tt.after, got, tt.decay, tt.decay+1)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// dragLabel is a label that reports drags so the borderless mini view can be
+// moved by grabbing its text.
+type dragLabel struct {
+	widget.Label
+	onDragged func(dx, dy float32)
+	onDragEnd func()
+}
+
+func newDragLabel(text string) *dragLabel {
+	l := &dragLabel{}
+	l.Text = text
+	l.ExtendBaseWidget(l)
+	return l
+}
+
+func (l *dragLabel) Dragged(e *fyne.DragEvent) {
+	if l.onDragged != nil {
+		l.onDragged(e.Dragged.DX, e.Dragged.DY)
+	}
+}
+
+func (l *dragLabel) DragEnd() {
+	if l.onDragEnd != nil {
+		l.onDragEnd()
+	}
+}
+
+// dragArea reports drags anywhere over its content, for borderless windows
+// whose content isn't a label.
+type dragArea struct {
+	widget.BaseWidget
+	content   fyne.CanvasObject
+	onDragged func(dx, dy float32)
+}
+
+func newDragArea(content fyne.CanvasObject) *dragArea {
+	d := &dragArea{content: content}
+	d.ExtendBaseWidget(d)
+	return d
+}
+
+func (d *dragArea) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(d.content)
+}
+
+func (d *dragArea) Dragged(e *fyne.DragEvent) {
+	if d.onDragged != nil {
+		d.onDragged(e.Dragged.DX, e.Dragged.DY)
+	}
+}
+
+func (d *dragArea) DragEnd() {}
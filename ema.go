@@ -0,0 +1,35 @@
+package main
+
+const defaultEMAAlpha = 0.2
+
+// updateEMA folds the latest current APM into an exponential moving average,
+// giving a calmer readout than the raw windowed count. Higher alpha follows
+// the raw value more closely.
+func (a *APMTracker) updateEMA(current int) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if !a.emaSeeded {
+		a.emaAPM, a.emaSeeded = float64(current), true
+		return
+	}
+	a.emaAPM += a.emaAlpha * (float64(current) - a.emaAPM)
+}
+
+func (a *APMTracker) smoothedAPM() float64 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.emaAPM
+}
+
+func (a *APMTracker) getEMAAlpha() float64 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.emaAlpha
+}
+
+func (a *APMTracker) setEMAAlpha(alpha float64) {
+	a.mutex.Lock()
+	a.emaAlpha = min(max(alpha, 0.01), 1)
+	a.mutex.Unlock()
+	a.saveConfig()
+}
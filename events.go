@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fyne.io/fyne/v2/widget"
+	"github.com/robotn/gohook"
+)
+
+// Synthetic mouse "buttons" so optional events show up in the key stats.
+const (
+	wheelCode uint16 = 0x100
+	dragCode  uint16 = 0x101
+)
+
+// eventOption describes an input event kind that can optionally be counted
+// as an action. Adding a new kind only needs a new entry in optionalEvents.
+type eventOption struct {
+	Name  string
+	Label string
+	Kind  uint8
+	Code  uint16
+	// Once limits counting to the first event between a mouse press and
+	// release, for kinds that fire continuously while held.
+	Once bool
+}
+
+var optionalEvents = []eventOption{
+	{Name: "scroll", Label: "Count scroll wheel", Kind: hook.MouseWheel, Code: wheelCode},
+	{Name: "drag", Label: "Count mouse drags", Kind: hook.MouseDrag, Code: dragCode, Once: true},
+}
+
+func findEventOption(kind uint8) (eventOption, bool) {
+	for _, opt := range optionalEvents {
+		if opt.Kind == kind {
+			return opt, true
+		}
+	}
+	return eventOption{}, false
+}
+
+func (a *APMTracker) countsEvent(name string) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.countEvents[name]
+}
+
+func (a *APMTracker) setCountsEvent(name string, on bool) {
+	a.mutex.Lock()
+	if on {
+		a.countEvents[name] = true
+	} else {
+		delete(a.countEvents, name)
+	}
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+func (a *APMTracker) eventChecks() []*widget.FormItem {
+	items := make([]*widget.FormItem, 0, len(optionalEvents))
+	for _, opt := range optionalEvents {
+		name := opt.Name
+		check := widget.NewCheck(opt.Label, func(on bool) {
+			a.setCountsEvent(name, on)
+		})
+		check.Checked = a.countsEvent(name)
+		items = append(items, widget.NewFormItem("", check))
+	}
+	return items
+}
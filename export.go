@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+func (a *APMTracker) exportCSV(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := a.writeCSV(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (a *APMTracker) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"timestamp", "actions", "rolling_apm", "cumulative"}); err != nil {
+		return err
+	}
+
+	data := a.actions.GetAll()
+	if len(data) > 0 {
+		start := a.startTime.UnixNano()
+		if data[0] < start {
+			start = data[0]
+		}
+		now := a.now().UnixNano()
+		second := int64(time.Second)
+		buckets := make([]int, (now-start)/second+1)
+		for _, t := range data {
+			if i := (t - start) / second; i < int64(len(buckets)) {
+				buckets[i]++
+			}
+		}
+
+		// Actions that fell out of the buffer still count towards the total.
+		rolling, cumulative := 0, max(int(a.getTotalActions())-len(data), 0)
+		for i, count := range buckets {
+			rolling += count
+			if i >= 60 {
+				rolling -= buckets[i-60]
+			}
+			cumulative += count
+			ts := time.Unix(0, start+int64(i)*second).Format(time.RFC3339)
+			record := []string{ts, strconv.Itoa(count), strconv.Itoa(rolling), strconv.Itoa(cumulative)}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteCSV(t *testing.T) {
+	a, clock := newTestTracker(t)
+	start := clock.now()
+	for _, at := range []time.Duration{500 * time.Millisecond, 1200 * time.Millisecond, 1500 * time.Millisecond} {
+		clock.t = start.Add(at)
+		a.addAction(KeyboardAction, 30)
+	}
+	// The export runs up to the clock's now, including quiet seconds.
+	clock.t = start.Add(3 * time.Second)
+
+	var b strings.Builder
+	if err := a.writeCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	ts := func(sec int) string {
+		return start.Add(time.Duration(sec) * time.Second).Local().Format(time.RFC3339)
+	}
+	want := "timestamp,actions,rolling_apm,cumulative\n" +
+		ts(0) + ",1,1,1\n" +
+		ts(1) + ",2,3,3\n" +
+		ts(2) + ",0,3,3\n" +
+		ts(3) + ",0,3,3\n"
+	if got := b.String(); got != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteCSVCumulativeAfterWrap(t *testing.T) {
+	a, clock := newTestTracker(t)
+	a.resizeActions(4)
+	for range 10 {
+		clock.advance(100 * time.Millisecond)
+		a.addAction(KeyboardAction, 30)
+	}
+
+	var b strings.Builder
+	if err := a.writeCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	rows := strings.Split(strings.TrimSpace(b.String()), "\n")
+	last := strings.Split(rows[len(rows)-1], ",")
+	if got := last[3]; got != "10" {
+		t.Errorf("final cumulative = %s, want 10", got)
+	}
+}
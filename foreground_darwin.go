@@ -0,0 +1,34 @@
+//go:build darwin
+
+package main
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Cocoa
+#import <Cocoa/Cocoa.h>
+#include <stdlib.h>
+#include <string.h>
+
+static char *apm_frontmost_app(void) {
+	@autoreleasepool {
+		NSRunningApplication *app = [[NSWorkspace sharedWorkspace] frontmostApplication];
+		NSString *name = [[app executableURL] lastPathComponent];
+		if (!name) {
+			return NULL;
+		}
+		return strdup([name UTF8String]);
+	}
+}
+*/
+import "C"
+
+import "unsafe"
+
+func foregroundProcess() (string, bool) {
+	name := C.apm_frontmost_app()
+	if name == nil {
+		return "", false
+	}
+	defer C.free(unsafe.Pointer(name))
+	return C.GoString(name), true
+}
@@ -0,0 +1,7 @@
+//go:build !windows && !darwin && !((linux || freebsd || openbsd || netbsd) && !wayland)
+
+package main
+
+func foregroundProcess() (string, bool) {
+	return "", false
+}
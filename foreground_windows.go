@@ -0,0 +1,43 @@
+//go:build windows
+
+package main
+
+import (
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                      = syscall.NewLazyDLL("kernel32.dll")
+	procGetForegroundWindow       = user32.NewProc("GetForegroundWindow")
+	procGetWindowThreadProcessID  = user32.NewProc("GetWindowThreadProcessId")
+	procQueryFullProcessImageName = kernel32.NewProc("QueryFullProcessImageNameW")
+)
+
+const processQueryLimitedInformation = 0x1000
+
+func foregroundProcess() (string, bool) {
+	hwnd, _, _ := procGetForegroundWindow.Call()
+	if hwnd == 0 {
+		return "", false
+	}
+	var pid uint32
+	procGetWindowThreadProcessID.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
+	if pid == 0 {
+		return "", false
+	}
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, pid)
+	if err != nil {
+		return "", false
+	}
+	defer syscall.CloseHandle(h)
+
+	buf := make([]uint16, syscall.MAX_PATH)
+	size := uint32(len(buf))
+	r, _, _ := procQueryFullProcessImageName.Call(uintptr(h), 0, uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)))
+	if r == 0 {
+		return "", false
+	}
+	return filepath.Base(syscall.UTF16ToString(buf[:size])), true
+}
@@ -0,0 +1,59 @@
+//go:build (linux || freebsd || openbsd || netbsd) && !wayland
+
+package main
+
+/*
+#cgo LDFLAGS: -lX11
+#include <X11/Xlib.h>
+#include <X11/Xatom.h>
+
+static unsigned long apm_window_cardinal(Display *d, Window w, const char *name, Atom type) {
+	Atom actual;
+	int format;
+	unsigned long n, after, value = 0;
+	unsigned char *data = NULL;
+	if (XGetWindowProperty(d, w, XInternAtom(d, name, False), 0, 1, False, type,
+			&actual, &format, &n, &after, &data) == Success && data) {
+		if (n > 0) {
+			value = *(unsigned long *)data;
+		}
+		XFree(data);
+	}
+	return value;
+}
+
+static unsigned long apm_active_pid(void) {
+	Display *d = XOpenDisplay(NULL);
+	if (!d) {
+		return 0;
+	}
+	unsigned long pid = 0;
+	Window active = apm_window_cardinal(d, DefaultRootWindow(d), "_NET_ACTIVE_WINDOW", XA_WINDOW);
+	if (active) {
+		pid = apm_window_cardinal(d, active, "_NET_WM_PID", XA_CARDINAL);
+	}
+	XCloseDisplay(d);
+	return pid;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// foregroundProcess resolves the active window's _NET_WM_PID through procfs,
+// so it reports nothing on systems without /proc.
+func foregroundProcess() (string, bool) {
+	pid := C.apm_active_pid()
+	if pid == 0 {
+		return "", false
+	}
+	comm, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(comm)), true
+}
@@ -0,0 +1,29 @@
+package main
+
+import "strings"
+
+// Freezing holds the labels and graphs at their last values without touching
+// counting: the update loop keeps ticking, sampling and folding stats, and
+// only skips drawing. Unlike pause, nothing is lost, and unfreezing catches
+// the display up at once.
+func (a *APMTracker) isFrozen() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.frozen
+}
+
+func (a *APMTracker) toggleFreeze() {
+	a.mutex.Lock()
+	a.frozen = !a.frozen
+	frozen := a.frozen
+	a.mutex.Unlock()
+
+	if frozen {
+		a.freezeButton.SetText("Unfreeze")
+		status, _ := a.statusVar.Get()
+		a.statusVar.Set(strings.TrimSpace(status + " FROZEN"))
+		return
+	}
+	a.freezeButton.SetText("Freeze")
+	a.refresh()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"sync"
+	"time"
+)
+
+// Gamepad buttons are reported after the synthetic mouse codes.
+const gamepadCode uint16 = 0x200
+
+const gamepadPollInterval = 10 * time.Millisecond
+
+// gamepadSource polls GLFW's gamepad mappings and emits an action for each
+// button press. Sticks and triggers are analog and never count, so holding
+// or sweeping them can't inflate APM. GLFW must be driven from the main
+// thread, which the window's native context provides, so gamepads are only
+// available while the GUI runs.
+type gamepadSource struct {
+	window fyne.Window
+	stop   chan struct{}
+	once   sync.Once
+}
+
+func newGamepadSource(w fyne.Window) *gamepadSource {
+	return &gamepadSource{window: w, stop: make(chan struct{})}
+}
+
+func (g *gamepadSource) Start() <-chan Action {
+	out := make(chan Action, 64)
+	go func() {
+		defer close(out)
+		last := make(map[glfw.Joystick]glfw.GamepadState)
+		ticker := time.NewTicker(gamepadPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-g.stop:
+				return
+			case <-ticker.C:
+			}
+			var pressed []uint16
+			runNative(g.window, func(any) {
+				for joy := glfw.Joystick1; joy <= glfw.JoystickLast; joy++ {
+					state := joy.GetGamepadState()
+					if state == nil {
+						delete(last, joy)
+						continue
+					}
+					for button, action := range state.Buttons {
+						if action == glfw.Press && last[joy].Buttons[button] != glfw.Press {
+							pressed = append(pressed, gamepadCode+uint16(button))
+						}
+					}
+					last[joy] = *state
+				}
+			})
+			for _, code := range pressed {
+				out <- Action{Kind: GamepadAction, Code: code}
+			}
+		}
+	}()
+	return out
+}
+
+func (g *gamepadSource) Stop() {
+	g.once.Do(func() {
+		close(g.stop)
+	})
+}
+
+func (a *APMTracker) isCountGamepad() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.countGamepad
+}
+
+func (a *APMTracker) setCountGamepad(on bool) {
+	a.mutex.Lock()
+	a.countGamepad = on
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.startGamepad()
+}
+
+// startGamepad starts or stops gamepad capture to match the setting.
+func (a *APMTracker) startGamepad() {
+	on := a.isCountGamepad()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	switch {
+	case on && a.gamepad == nil && a.window != nil:
+		a.gamepad = newGamepadSource(a.window)
+		go a.consume(a.gamepad)
+	case !on && a.gamepad != nil:
+		a.gamepad.Stop()
+		a.gamepad = nil
+	}
+}
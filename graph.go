@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	minGraphWidth = 400
+	graphHeight   = 300
+)
+
+// GraphRenderer draws bucketed action counts, newest bucket on the right. It
+// knows nothing about the tracker so the drawing can evolve on its own.
+type GraphRenderer struct {
+	Palette  Palette
+	BarColor color.Color
+	BarWidth int
+	Mode     GraphMode
+	// Stack, when set, is the mouse share of each bucket. Bars then show the
+	// keyboard share in BarColor with the mouse share stacked above it.
+	Stack []int
+	// FixedMax pins the top of the Y axis to this APM; zero autoscales.
+	FixedMax int
+	// Dots marks the top of each bucket.
+	Dots bool
+	// Current, when positive, draws a solid line at that APM.
+	Current int
+	// Compare, when set, is another session's buckets drawn faintly behind
+	// these; negative counts mark buckets it has no data for.
+	Compare []int
+	// MovingAvg, when positive, draws the average over that many buckets.
+	MovingAvg int
+	// Buffer, when set, supplies the image to draw into instead of allocating
+	// a new one per frame.
+	Buffer *imageBuffer
+}
+
+// imageBuffer reuses images across frames. It hands out two in turn so the
+// image being drawn is never the one on screen.
+type imageBuffer struct {
+	mutex  sync.Mutex
+	images [2]*image.RGBA
+	next   int
+}
+
+// get returns an image of the given size. Its contents are stale, so callers
+// must clear it first.
+func (b *imageBuffer) get(width, height int) *image.RGBA {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	img := b.images[b.next]
+	if img == nil || img.Rect.Dx() != width || img.Rect.Dy() != height {
+		img = image.NewRGBA(image.Rect(0, 0, width, height))
+		b.images[b.next] = img
+	}
+	b.next = 1 - b.next
+	return img
+}
+
+func newImage(buf *imageBuffer, width, height int) *image.RGBA {
+	if buf == nil {
+		return image.NewRGBA(image.Rect(0, 0, width, height))
+	}
+	return buf.get(width, height)
+}
+
+func clearImage(img *image.RGBA, c color.Color) {
+	draw.Draw(img, img.Rect, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func (r GraphRenderer) stride() int {
+	return r.BarWidth + 1
+}
+
+// Render draws buckets spanning span in total. Each bucket's count is scaled
+// to actions per minute for the axis labels and the target line.
+func (r GraphRenderer) Render(buckets []int, span time.Duration, target int) *image.RGBA {
+	// Widen the image rather than overlap bars when they no longer fit.
+	width := max(minGraphWidth, len(buckets)*r.stride())
+	img := newImage(r.Buffer, width, graphHeight)
+	r.background(img)
+
+	// scale is the bucket count drawn at full height: the busiest bucket, or
+	// the fixed axis maximum converted from APM.
+	perMinute := float64(time.Minute) / float64(span/time.Duration(len(buckets)))
+	scale := 0.0
+	for _, count := range buckets {
+		scale = max(scale, float64(count))
+	}
+	for _, count := range r.Compare {
+		scale = max(scale, float64(count))
+	}
+	if r.FixedMax > 0 {
+		scale = float64(r.FixedMax) / perMinute
+	}
+	if scale > 0 {
+		if r.Compare != nil {
+			r.compareLine(img, r.Compare, scale)
+		}
+		switch r.Mode {
+		case LineGraph:
+			r.line(img, buckets, scale)
+		default:
+			r.bars(img, buckets, scale)
+		}
+		if r.MovingAvg > 0 {
+			r.maLine(img, buckets, scale)
+		}
+		if r.Dots {
+			r.dots(img, buckets, scale)
+		}
+	}
+
+	maxAPM := scale * perMinute
+	if target > 0 && scale > 0 {
+		y := graphHeight - 1 - int(float64(target)/maxAPM*graphHeight)
+		drawDashedLine(img, y, r.Palette.Target)
+	}
+	if r.Current > 0 && scale > 0 {
+		currentLine(img, r.Current, maxAPM, r.Palette.Axis)
+	}
+	r.axes(img, maxAPM, span)
+	if r.Stack != nil && r.Mode != LineGraph {
+		r.legend(img)
+	}
+	return img
+}
+
+func (r GraphRenderer) background(img *image.RGBA) {
+	clearImage(img, r.Palette.Background)
+	grid := image.NewUniform(r.Palette.Grid)
+	for i := 1; i < 4; i++ {
+		y := img.Rect.Dy() * i / 4
+		draw.Draw(img, image.Rect(0, y, img.Rect.Dx(), y+1), grid, image.Point{}, draw.Src)
+	}
+}
+
+// bars clamps anything past scale to the top of the image.
+func (r GraphRenderer) bars(img *image.RGBA, buckets []int, scale float64) {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	fill := image.NewUniform(r.BarColor)
+	stack := image.NewUniform(r.Palette.Mouse)
+	for i, count := range buckets {
+		barHeight := min(int(float64(count)/scale*float64(height)), height)
+		x := width - (i+1)*r.stride()
+		bar := image.Rect(x, height-barHeight, x+r.BarWidth, height).Intersect(img.Rect)
+		draw.Draw(img, bar, fill, image.Point{}, draw.Src)
+		if r.Stack != nil && r.Stack[i] > 0 {
+			// Mouse share sits on top, so the keyboard share keeps the baseline.
+			mouseHeight := int(float64(r.Stack[i]) / float64(count) * float64(barHeight))
+			top := image.Rect(x, height-barHeight, x+r.BarWidth, height-barHeight+mouseHeight).Intersect(img.Rect)
+			draw.Draw(img, top, stack, image.Point{}, draw.Src)
+		}
+	}
+}
+
+func (r GraphRenderer) legend(img *image.RGBA) {
+	x := img.Rect.Dx() - 120
+	for i, entry := range []struct {
+		label string
+		c     color.Color
+	}{{"Keys", r.BarColor}, {"Mouse", r.Palette.Mouse}} {
+		left := x + i*60
+		draw.Draw(img, image.Rect(left, 5, left+8, 13), image.NewUniform(entry.c), image.Point{}, draw.Src)
+		drawText(img, left+11, 13, entry.label, r.Palette.Axis)
+	}
+}
+
+func (r GraphRenderer) line(img *image.RGBA, buckets []int, scale float64) {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	prevX, prevY := 0, 0
+	for i, count := range buckets {
+		barHeight := min(int(float64(count)/scale*float64(height-1)), height-1)
+		x := width - (i+1)*r.stride() + r.BarWidth/2
+		y := height - 1 - barHeight
+		if i > 0 {
+			drawLine(img, prevX, prevY, x, y, r.BarColor)
+		}
+		prevX, prevY = x, y
+	}
+}
+
+func (r GraphRenderer) axes(img *image.RGBA, maxAPM float64, span time.Duration) {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	drawText(img, 4, 13, fmt.Sprintf("%.0f APM", maxAPM), r.Palette.Axis)
+	drawText(img, 4, height/2+4, fmt.Sprintf("%.0f", maxAPM/2), r.Palette.Axis)
+	drawText(img, 4, height-4, "-"+formatAgo(span), r.Palette.Axis)
+	half := "-" + formatAgo(span/2)
+	drawText(img, width/2-len(half)*7/2, height-4, half, r.Palette.Axis)
+	drawText(img, width-25, height-4, "now", r.Palette.Axis)
+}
+
+// setPixel ignores coordinates outside the image so that layout changes such
+// as more buckets or wider bars can never write past the edges.
+func setPixel(img *image.RGBA, x, y int, c color.Color) {
+	if !image.Pt(x, y).In(img.Rect) {
+		return
+	}
+	img.Set(x, y, c)
+}
+
+func drawText(img *image.RGBA, x, y int, text string, c color.Color) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
+
+// drawLine plots a two-pixel-thick segment so diagonal runs look less
+// stair-stepped.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := x1-x0, y1-y0
+	steps := int(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy))))
+	if steps == 0 {
+		setPixel(img, x0, y0, c)
+		return
+	}
+	for i := 0; i <= steps; i++ {
+		x := x0 + int(math.Round(float64(dx*i)/float64(steps)))
+		y := y0 + int(math.Round(float64(dy*i)/float64(steps)))
+		setPixel(img, x, y, c)
+		setPixel(img, x, y-1, c)
+	}
+}
+
+func (a *APMTracker) isWallClock() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.wallClock
+}
+
+func (a *APMTracker) setWallClock(on bool) {
+	a.mutex.Lock()
+	a.wallClock = on
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.updateGraph()
+}
+
+// slotEnd is the last instant of the wall-clock slot holding now. Bucketing
+// back from it puts each action in slot floor(t/bucket), so a bar fills while
+// its slot is current and then holds still as it ages, rather than every
+// bucket edge sliding with the clock.
+func slotEnd(now time.Time, bucket time.Duration) time.Time {
+	return now.Truncate(bucket).Add(bucket - 1)
+}
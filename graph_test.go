@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+	"time"
+)
+
+func testBuckets() []int {
+	buckets := make([]int, 60)
+	for i := range buckets {
+		buckets[i] = (i * 7) % 13
+	}
+	return buckets
+}
+
+func testRenderer(buf *imageBuffer) GraphRenderer {
+	return GraphRenderer{Palette: lightPalette, BarColor: lightPalette.Bar, BarWidth: 5, Buffer: buf}
+}
+
+// countColor counts the pixels of color c above the axis labels along the
+// bottom edge.
+func countColor(img *image.RGBA, c color.Color) int {
+	n := 0
+	for y := 0; y < graphHeight-20; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			if img.At(x, y) == c {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestRenderBars(t *testing.T) {
+	// The busiest bucket fills the 300px height, so bars are 150, 300, 0 and
+	// 75px tall; 280 rows lie above the labels.
+	buckets := []int{10, 20, 0, 5}
+	tests := []struct {
+		name       string
+		stack      []int
+		bar, mouse int
+	}{
+		{"plain", nil, (130 + 280 + 0 + 55) * 5, 0},
+		// The first bar is half mouse and the last all mouse; the legend adds
+		// an 8x8 swatch of each color.
+		{"stacked", []int{5, 0, 0, 5}, (55+280)*5 + 64, (75+55)*5 + 64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := testRenderer(nil)
+			r.Stack = tt.stack
+			img := r.Render(buckets, 4*time.Second, 0)
+			if got := countColor(img, lightPalette.Bar); got != tt.bar {
+				t.Errorf("bar pixels = %d, want %d", got, tt.bar)
+			}
+			if got := countColor(img, lightPalette.Mouse); got != tt.mouse {
+				t.Errorf("mouse pixels = %d, want %d", got, tt.mouse)
+			}
+		})
+	}
+}
+
+// A reused image starts each frame with the last frame's pixels, so it must
+// come out the same as a fresh one.
+func TestRenderReusedImage(t *testing.T) {
+	var buf imageBuffer
+	reused := testRenderer(&buf)
+	fresh := testRenderer(nil)
+	frames := [][]int{testBuckets(), make([]int, 60), testBuckets()[:30]}
+	for i, buckets := range frames {
+		got := reused.Render(buckets, time.Minute, 100)
+		want := fresh.Render(buckets, time.Minute, 100)
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("frame %d differs from a freshly allocated render", i)
+		}
+	}
+}
+
+// Render must stay inside the image however extreme the counts and layout.
+func TestRenderPathological(t *testing.T) {
+	huge := func(n int) []int {
+		buckets := make([]int, n)
+		for i := range buckets {
+			buckets[i] = math.MaxInt32 - i
+		}
+		return buckets
+	}
+	tests := []struct {
+		name    string
+		buckets []int
+		span    time.Duration
+		target  int
+		tweak   func(*GraphRenderer)
+	}{
+		{"huge counts", huge(60), time.Minute, 100, nil},
+		{"one bucket over a nanosecond", testBuckets()[:1], time.Nanosecond, math.MaxInt32, nil},
+		{"wider than the image", huge(500), time.Minute, 100, func(r *GraphRenderer) { r.BarWidth = 40 }},
+		{"zero bar width", huge(60), time.Minute, 100, func(r *GraphRenderer) { r.BarWidth = 0 }},
+		{"fixed max below the counts", huge(60), time.Minute, 1, func(r *GraphRenderer) { r.FixedMax = 1 }},
+		{"stack above the count", testBuckets(), time.Minute, 100, func(r *GraphRenderer) { r.Stack = huge(60) }},
+		{"line", huge(200), time.Minute, -5, func(r *GraphRenderer) { r.Mode = LineGraph }},
+		{"overlays", huge(60), time.Minute, 100, func(r *GraphRenderer) {
+			r.Dots, r.Current, r.MovingAvg, r.Compare = true, math.MaxInt32, 600, huge(90)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := testRenderer(nil)
+			if tt.tweak != nil {
+				tt.tweak(&r)
+			}
+			img := r.Render(tt.buckets, tt.span, tt.target)
+			if img.Rect.Dy() != graphHeight || img.Rect.Dx() < minGraphWidth {
+				t.Errorf("image is %v", img.Rect)
+			}
+		})
+	}
+}
+
+// clearByPixel is how frames were cleared before draw.Draw.
+func clearByPixel(img *image.RGBA, c color.Color) {
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+}
+
+func BenchmarkClearImage(b *testing.B) {
+	img := image.NewRGBA(image.Rect(0, 0, minGraphWidth, graphHeight))
+	b.Run("per-pixel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			clearByPixel(img, color.White)
+		}
+	})
+	b.Run("draw", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			clearImage(img, color.White)
+		}
+	})
+}
+
+func BenchmarkGraphRender(b *testing.B) {
+	buckets := testBuckets()
+	b.Run("new image", func(b *testing.B) {
+		r := testRenderer(nil)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r.Render(buckets, time.Minute, 100)
+		}
+	})
+	b.Run("reused image", func(b *testing.B) {
+		r := testRenderer(&imageBuffer{})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r.Render(buckets, time.Minute, 100)
+		}
+	})
+}
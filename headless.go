@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func (a *APMTracker) RunHeadless() {
+	fmt.Println(versionString())
+	a.headless = true
+	a.loadConfig()
+	a.loadRecords()
+	if a.metricsAddr != "" {
+		a.startMetricsServer(a.metricsAddr)
+	}
+	if a.wsPort != 0 {
+		a.startWebSocketServer(a.wsPort)
+	}
+	if a.dashboardAddr != "" {
+		a.startDashboard(a.dashboardAddr)
+	}
+	prefix := ""
+	if a.selfTest > 0 {
+		prefix = "[self-test, synthetic input] "
+		a.startSelfTest()
+	} else {
+		a.startCapture()
+	}
+	go a.watchForeground()
+
+	// main's signal handler takes care of shutting down.
+	timer := time.NewTimer(a.getUpdateInterval())
+	for range timer.C {
+		a.checkIdle()
+		a.sampleAPM()
+		stats := a.updateStats()
+		a.updateMinAPM(stats)
+		a.updateRecords(stats)
+		a.broadcast(stats)
+		a.checkLowAPM(stats)
+		fmt.Printf("%s%s current=%d peak=%d average=%.2f\n",
+			prefix, time.Now().Format("15:04:05"), stats.Current, stats.Peak, stats.Average)
+		timer.Reset(a.getUpdateInterval())
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+)
+
+const (
+	heatmapColumns  = 60
+	heatmapMaxRows  = 36
+	heatmapCellSize = 6
+	heatmapSlice    = 5 * time.Second
+)
+
+// heatStops runs from cool to hot; heatColor interpolates between them.
+var heatStops = []color.RGBA{
+	{R: 0x1e, G: 0x3a, B: 0x8a, A: 0xff},
+	{R: 0x06, G: 0xb6, B: 0xd4, A: 0xff},
+	{R: 0x22, G: 0xc5, B: 0x5e, A: 0xff},
+	{R: 0xea, G: 0xb3, B: 0x08, A: 0xff},
+	{R: 0xdc, G: 0x26, B: 0x26, A: 0xff},
+}
+
+func heatColor(t float64) color.RGBA {
+	t = min(max(t, 0), 1) * float64(len(heatStops)-1)
+	i := min(int(t), len(heatStops)-2)
+	f := t - float64(i)
+	from, to := heatStops[i], heatStops[i+1]
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*f)
+	}
+	return color.RGBA{R: mix(from.R, to.R), G: mix(from.G, to.G), B: mix(from.B, to.B), A: 0xff}
+}
+
+// heatmapSliceFor widens the slice for long sessions so the whole session
+// still fits in the grid.
+func heatmapSliceFor(elapsed time.Duration) time.Duration {
+	slice := heatmapSlice
+	for elapsed > slice*heatmapColumns*heatmapMaxRows {
+		slice *= 2
+	}
+	return slice
+}
+
+func (a *APMTracker) updateHeatmap() {
+	width, height := 400, 300
+	labelWidth := 40
+	img := a.heatmapBuf.get(width, height)
+	palette := a.getPalette()
+	clearImage(img, palette.Background)
+
+	a.mutex.Lock()
+	start := a.startTime
+	a.mutex.Unlock()
+	elapsed := a.now().Sub(start)
+	slice := heatmapSliceFor(elapsed)
+	sliceCount := int(elapsed/slice) + 1
+
+	counts := make([]int, sliceCount)
+	a.actions.ForEach(func(t int64) {
+		i := int(time.Duration(t-start.UnixNano()) / slice)
+		if i >= 0 && i < sliceCount {
+			counts[i]++
+		}
+	})
+	perMinute := float64(time.Minute) / float64(slice)
+	maxAPM := 0.0
+	for _, count := range counts {
+		maxAPM = max(maxAPM, float64(count)*perMinute)
+	}
+
+	for i, count := range counts {
+		row, col := i/heatmapColumns, i%heatmapColumns
+		x0, y0 := labelWidth+col*heatmapCellSize, row*heatmapCellSize
+		c := palette.Grid
+		if maxAPM > 0 {
+			c = heatColor(float64(count) * perMinute / maxAPM)
+		}
+		for y := y0; y < y0+heatmapCellSize-1; y++ {
+			for x := x0; x < x0+heatmapCellSize-1; x++ {
+				setPixel(img, x, y, c)
+			}
+		}
+		if col == 0 && row%5 == 0 {
+			offset := time.Duration(i) * slice
+			drawText(img, 2, y0+heatmapCellSize+4, fmt.Sprintf("%dm", int(offset.Minutes())), palette.Axis)
+		}
+	}
+
+	legendTop := height - 30
+	legendWidth := heatmapColumns * heatmapCellSize
+	for x := 0; x < legendWidth; x++ {
+		c := heatColor(float64(x) / float64(legendWidth-1))
+		for y := legendTop; y < legendTop+8; y++ {
+			setPixel(img, labelWidth+x, y, c)
+		}
+	}
+	drawText(img, labelWidth, legendTop+22, "0", palette.Axis)
+	maxLabel := fmt.Sprintf("%.0f APM", maxAPM)
+	drawText(img, labelWidth+legendWidth-len(maxLabel)*7, legendTop+22, maxLabel, palette.Axis)
+	drawText(img, labelWidth+legendWidth/2-30, legendTop+22, fmt.Sprintf("%s cells", slice), palette.Axis)
+
+	a.heatmapImage.Image = img
+	a.heatmapImage.Refresh()
+}
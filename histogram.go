@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+)
+
+const (
+	defaultHistogramWidth = 25
+	maxHistogramRows      = 12
+)
+
+var histogramWidths = []int{10, 25, 50, 100}
+
+// histogramBuckets groups samples into rows of bucketWidth APM, folding
+// anything past the last row into it so the chart keeps a bounded height.
+func histogramBuckets(samples []int, bucketWidth int) []int {
+	maxSample := 0
+	for _, s := range samples {
+		if s > maxSample {
+			maxSample = s
+		}
+	}
+	rows := maxSample/bucketWidth + 1
+	if rows > maxHistogramRows {
+		rows = maxHistogramRows
+	}
+	buckets := make([]int, rows)
+	for _, s := range samples {
+		i := s / bucketWidth
+		if i >= rows {
+			i = rows - 1
+		}
+		buckets[i]++
+	}
+	return buckets
+}
+
+func (a *APMTracker) getHistogramWidth() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.histogramWidth
+}
+
+func (a *APMTracker) setHistogramWidth(w int) {
+	a.mutex.Lock()
+	a.histogramWidth = w
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.updateHistogram()
+}
+
+func (a *APMTracker) updateHistogram() {
+	width, height := 400, 300
+	labelWidth, pctWidth := 70, 45
+	img := a.histogramBuf.get(width, height)
+	palette := a.getPalette()
+	clearImage(img, palette.Background)
+
+	samples := a.apmSamples.GetAll()
+	if len(samples) == 0 {
+		drawText(img, width/2-60, height/2, "No samples yet", palette.Axis)
+		a.histogramImage.Image = img
+		a.histogramImage.Refresh()
+		return
+	}
+
+	bucketWidth := a.getHistogramWidth()
+	buckets := histogramBuckets(samples, bucketWidth)
+	maxCount := 0
+	for _, count := range buckets {
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+
+	rowHeight := height / maxHistogramRows
+	barSpace := width - labelWidth - pctWidth
+	for i, count := range buckets {
+		top := i * rowHeight
+		label := fmt.Sprintf("%d-%d", i*bucketWidth, (i+1)*bucketWidth)
+		if i == len(buckets)-1 && i == maxHistogramRows-1 {
+			label = fmt.Sprintf("%d+", i*bucketWidth)
+		}
+		drawText(img, 4, top+rowHeight/2+4, label, palette.Axis)
+
+		barLength := int(float64(count) / float64(maxCount) * float64(barSpace))
+		for y := top + 2; y < top+rowHeight-2; y++ {
+			for x := labelWidth; x < labelWidth+barLength; x++ {
+				setPixel(img, x, y, palette.Bar)
+			}
+		}
+		pct := float64(count) / float64(len(samples)) * 100
+		drawText(img, width-pctWidth+4, top+rowHeight/2+4, fmt.Sprintf("%.0f%%", pct), palette.Axis)
+	}
+
+	a.histogramImage.Image = img
+	a.histogramImage.Refresh()
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// maxHistory is how many sessions the history keeps. The file is only
+	// trimmed once it runs historySlack past that, so most exits just append.
+	maxHistory   = 1000
+	historySlack = 100
+)
+
+// HistoryEntry summarizes one finished session, one JSON object per line.
+type HistoryEntry struct {
+	Date     time.Time `json:"date"`
+	Duration int       `json:"duration_seconds"`
+	Peak     int       `json:"peak_apm"`
+	Average  float64   `json:"average_apm"`
+	Total    int64     `json:"total_actions"`
+	Profile  string    `json:"profile,omitempty"`
+}
+
+func historyPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+func (a *APMTracker) historyEntry() HistoryEntry {
+	avg := a.calculateAverageAPM()
+	elapsed := a.activeElapsed()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return HistoryEntry{
+		Date:     a.startTime,
+		Duration: int(elapsed.Seconds()),
+		Peak:     a.peakAPM,
+		Average:  avg,
+		Total:    a.totalActions,
+		Profile:  a.profile,
+	}
+}
+
+// appendHistory adds a line with a single append-mode write, which other
+// instances exiting at the same time can't interleave with.
+func appendHistory(path string, entry HistoryEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return trimHistory(path)
+}
+
+// trimHistory keeps the newest maxHistory lines. The rewrite goes through a
+// rename so readers never see a partial file; an entry appended by another
+// instance mid-trim may be lost, which is cheaper than locking.
+func trimHistory(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	// The final newline leaves an empty element that isn't an entry.
+	if n := len(lines); n > 0 && len(lines[n-1]) == 0 {
+		lines = lines[:n-1]
+	}
+	if len(lines) <= maxHistory+historySlack {
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "history-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(bytes.Join(lines[len(lines)-maxHistory:], nil)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadHistory skips lines it can't parse, such as one cut short by a crash.
+// Entries come back oldest first even when overlapping runs exited out of
+// order.
+func loadHistory(path string) ([]HistoryEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	slices.SortStableFunc(entries, func(x, y HistoryEntry) int {
+		return x.Date.Compare(y.Date)
+	})
+	return entries, scanner.Err()
+}
+
+func (a *APMTracker) showHistory() {
+	path, err := historyPath()
+	var entries []HistoryEntry
+	if err == nil {
+		entries, err = loadHistory(path)
+	}
+
+	chart := canvas.NewImageFromImage(nil)
+	chart.FillMode = canvas.ImageFillOriginal
+	chart.SetMinSize(fyne.NewSize(trendWidth, trendHeight))
+	table := widget.NewLabel("")
+	table.TextStyle = fyne.TextStyle{Monospace: true}
+	show := func(profile string) {
+		shown := entries
+		if profile != allProfiles {
+			shown = nil
+			for _, e := range entries {
+				if e.Profile == profile {
+					shown = append(shown, e)
+				}
+			}
+		}
+		chart.Image = TrendRenderer{Palette: a.getPalette()}.Render(shown)
+		chart.Refresh()
+		table.SetText(historyTable(shown, err))
+	}
+
+	profiles := []string{allProfiles}
+	for _, e := range entries {
+		if e.Profile != "" && !slices.Contains(profiles, e.Profile) {
+			profiles = append(profiles, e.Profile)
+		}
+	}
+	profileSelect := widget.NewSelect(profiles, show)
+	profileSelect.SetSelected(allProfiles)
+
+	w := a.app.NewWindow("History")
+	top := container.NewVBox(container.NewHBox(widget.NewLabel("Profile"), profileSelect), chart)
+	w.SetContent(container.NewBorder(top, nil, nil, nil, container.NewScroll(table)))
+	w.Resize(fyne.NewSize(trendWidth+40, 520))
+	w.Show()
+}
+
+func historyTable(entries []HistoryEntry, err error) string {
+	var b strings.Builder
+	switch {
+	case err != nil:
+		fmt.Fprintf(&b, "Could not read history: %v\n", err)
+	case len(entries) == 0:
+		b.WriteString("No sessions recorded yet.\n")
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		fmt.Fprintf(&b, "%s  %8s  peak %4d  avg %7.2f  %d actions\n",
+			e.Date.Format("2006-01-02 15:04"), formatClock(time.Duration(e.Duration)*time.Second),
+			e.Peak, e.Average, e.Total)
+	}
+	return b.String()
+}
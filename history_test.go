@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrimHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	for i := 0; i <= maxHistory+historySlack; i++ {
+		if err := appendHistory(path, HistoryEntry{Total: int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(data, []byte("\n")); n != maxHistory {
+		t.Errorf("kept %d entries, want %d", n, maxHistory)
+	}
+	entries, err := loadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := entries[len(entries)-1].Total; last != maxHistory+historySlack {
+		t.Errorf("newest entry total = %d, want %d", last, maxHistory+historySlack)
+	}
+}
+
+func TestHistoryEntryDuration(t *testing.T) {
+	a, clock := newTestTracker(t)
+	clock.advance(10 * time.Minute)
+	a.pausedTotal = 2 * time.Minute
+	if got := a.historyEntry().Duration; got != 8*60 {
+		t.Errorf("duration = %ds, want 480s of active time", got)
+	}
+}
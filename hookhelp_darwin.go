@@ -0,0 +1,6 @@
+//go:build darwin
+
+package main
+
+const hookRemedy = "Allow apmgo under System Settings › Privacy & Security › Accessibility " +
+	"and Input Monitoring, then restart it."
@@ -0,0 +1,6 @@
+//go:build !windows && !darwin
+
+package main
+
+const hookRemedy = "Capture needs an X11 session with the XRecord extension and DISPLAY set. " +
+	"Wayland sessions don't allow global input hooks; log in to an X11 session instead."
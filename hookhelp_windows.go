@@ -0,0 +1,6 @@
+//go:build windows
+
+package main
+
+const hookRemedy = "Windows refused the low-level input hook. If the game runs as " +
+	"administrator, run apmgo as administrator too."
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"github.com/robotn/gohook"
+	"sync"
+)
+
+// gohookSource turns global keyboard and mouse events into actions. Filtering
+// that depends on raw events stays here: held and filtered keys, the toggle
+// hotkey, our own shortcuts, grabs of the mini view and optional event kinds.
+type gohookSource struct {
+	tracker *APMTracker
+	alive   chan struct{}
+	once    sync.Once
+}
+
+func (s *gohookSource) Start() <-chan Action {
+	out := make(chan Action, 256)
+	s.tracker.inputLog.status("starting hook")
+	s.alive = make(chan struct{})
+	done := make(chan struct{})
+	go s.tracker.watchHook(s.alive, done)
+	go func() {
+		defer close(done)
+		s.run(hook.Start(), out)
+	}()
+	return out
+}
+
+// Stop ends the hook, which closes its event channel and so the source's.
+// gohook panics if ended twice, so callers must stop it exactly once.
+func (s *gohookSource) Stop() {
+	s.tracker.inputLog.status("stopping hook")
+	hook.End()
+}
+
+func (s *gohookSource) run(events chan hook.Event, out chan<- Action) {
+	defer close(out)
+	a := s.tracker
+	defer a.inputLog.status("hook ended")
+	keys := func(codes []uint16) {
+		for _, code := range codes {
+			if a.countsKey(code) {
+				out <- Action{Kind: KeyboardAction, Code: code}
+			}
+		}
+	}
+
+	press := func(code uint16) {
+		fired, codes := a.hotkey.KeyDown(code)
+		keys(codes)
+		if fired {
+			a.toggleView()
+		}
+	}
+	// Shortcut modifiers pressed in our window are held back until the next
+	// key shows whether they start one of our shortcuts.
+	var pendingMods []uint16
+	releaseMods := func() {
+		for _, code := range pendingMods {
+			press(code)
+		}
+		pendingMods = nil
+	}
+
+	var heldCounted bool
+	for ev := range events {
+		a.inputLog.event(ev)
+		s.once.Do(func() { close(s.alive) })
+		switch ev.Kind {
+		case hook.HookEnabled:
+			a.inputLog.status("hook enabled, receiving events")
+		// KeyDown is the typed event, which carries no keycode and never
+		// fires for modifiers; KeyHold is the press itself, repeated while
+		// the OS auto-repeats.
+		case hook.KeyHold:
+			if a.keyPressed(ev.Keycode) {
+				continue
+			}
+			if a.isShortcut(ev.Keycode) {
+				// Modifiers held back, here or as part of the toggle chord,
+				// belong to the shortcut, so they aren't released to be counted.
+				pendingMods = nil
+				a.hotkey.Flush()
+				continue
+			}
+			if isShortcutModifier(ev.Keycode) && a.isFocused() {
+				pendingMods = append(pendingMods, ev.Keycode)
+				continue
+			}
+			releaseMods()
+			press(ev.Keycode)
+		case hook.KeyUp:
+			releaseMods()
+			a.keyReleased(ev.Keycode)
+			keys(a.hotkey.KeyUp(ev.Keycode))
+		case hook.MouseDown:
+			releaseMods()
+			keys(a.hotkey.Flush())
+			heldCounted = false
+			// Grabbing the mini view to drag it is not gameplay.
+			if a.insideMiniView(int(ev.X), int(ev.Y)) {
+				heldCounted = true
+				continue
+			}
+			out <- Action{Kind: MouseAction, Code: ev.Button}
+		case hook.MouseUp:
+			heldCounted = false
+		default:
+			opt, ok := findEventOption(ev.Kind)
+			if !ok || !a.countsEvent(opt.Name) {
+				continue
+			}
+			if opt.Once {
+				if heldCounted {
+					continue
+				}
+				heldCounted = true
+			}
+			out <- Action{Kind: MouseAction, Code: opt.Code}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/robotn/gohook"
+	"testing"
+)
+
+// eventSource runs scripted hook events through gohookSource's filtering.
+type eventSource struct {
+	gohookSource
+	events []hook.Event
+}
+
+func (s *eventSource) Start() <-chan Action {
+	out := make(chan Action, len(s.events))
+	events := make(chan hook.Event, len(s.events))
+	for _, ev := range s.events {
+		events <- ev
+	}
+	close(events)
+	s.alive = make(chan struct{})
+	go s.run(events, out)
+	return out
+}
+
+func (s *eventSource) Stop() {}
+
+// feedEvents counts events as if the hook had delivered them.
+func feedEvents(a *APMTracker, events ...hook.Event) {
+	a.consume(&eventSource{gohookSource: gohookSource{tracker: a}, events: events})
+}
+
+func press(name string) hook.Event {
+	return hook.Event{Kind: hook.KeyHold, Keycode: hook.Keycode[name]}
+}
+
+func release(name string) hook.Event {
+	return hook.Event{Kind: hook.KeyUp, Keycode: hook.Keycode[name]}
+}
+
+func TestKeyPressRecordsKeycode(t *testing.T) {
+	a, _ := newTestTracker(t)
+	// The typed event that follows a press has no keycode and is not counted.
+	typed := hook.Event{Kind: hook.KeyDown, Keychar: 'a'}
+	feedEvents(a, press("a"), typed, release("a"), press("s"), release("s"), press("a"), release("a"))
+
+	want := []KeyCount{{hook.Keycode["a"], 2}, {hook.Keycode["s"], 1}}
+	got := a.keyStats.Top(10)
+	if len(got) != len(want) {
+		t.Fatalf("key stats = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("key stats[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if n := a.getTotalActions(); n != 3 {
+		t.Errorf("total actions = %d, want 3", n)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// hookTimeout is how long the hook gets to report itself enabled. gohook
+// gives no error when the OS refuses it; events just never arrive.
+const hookTimeout = 5 * time.Second
+
+// watchHook reports a hook that never comes alive, and clears the report if
+// it does later on.
+func (a *APMTracker) watchHook(alive, done <-chan struct{}) {
+	timer := time.NewTimer(hookTimeout)
+	defer timer.Stop()
+	select {
+	case <-alive:
+		return
+	case <-done:
+		return
+	case <-timer.C:
+	}
+	a.hookFailed()
+	select {
+	case <-alive:
+		a.inputLog.status("hook recovered")
+		if a.hookBanner != nil {
+			a.hookBanner.Hide()
+		}
+	case <-done:
+	}
+}
+
+func (a *APMTracker) hookFailed() {
+	msg := fmt.Sprintf("no input events after %s; the input hook failed to start", hookTimeout)
+	a.inputLog.status("%s", msg)
+	if a.headless {
+		fmt.Fprintf(os.Stderr, "error: %s\n%s\n", msg, hookRemedy)
+		os.Exit(1)
+	}
+	if a.hookBanner != nil {
+		a.hookBanner.SetText("Not capturing input: the input hook failed to start.\n" + hookRemedy)
+		a.hookBanner.Show()
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"github.com/robotn/gohook"
+	"strings"
+	"sync"
+)
+
+const defaultHotkey = "ctrl+alt+m"
+
+// Hotkey recognises a global key chord. Presses of keys that belong to the
+// chord are held back until it is either completed, in which case they are
+// dropped, or abandoned, in which case they are released to be counted.
+type Hotkey struct {
+	mutex   sync.Mutex
+	combo   string
+	keys    []uint16
+	pressed map[uint16]bool
+	held    []uint16
+}
+
+func NewHotkey(combo string) (*Hotkey, error) {
+	h := &Hotkey{}
+	if err := h.Set(combo); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func parseHotkey(combo string) ([]uint16, error) {
+	var keys []uint16
+	for _, name := range strings.Split(strings.ToLower(combo), "+") {
+		name = strings.TrimSpace(name)
+		code, ok := hook.Keycode[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q in hotkey %q", name, combo)
+		}
+		keys = append(keys, code)
+	}
+	return keys, nil
+}
+
+func (h *Hotkey) Set(combo string) error {
+	keys, err := parseHotkey(combo)
+	if err != nil {
+		return err
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.combo = combo
+	h.keys = keys
+	h.pressed = make(map[uint16]bool, len(keys))
+	h.held = nil
+	return nil
+}
+
+func (h *Hotkey) String() string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.combo
+}
+
+func (h *Hotkey) contains(code uint16) bool {
+	for _, k := range h.keys {
+		if k == code {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyDown reports whether code completed the chord, along with the keys,
+// including this one, whose presses should now be counted as actions.
+func (h *Hotkey) KeyDown(code uint16) (fired bool, keys []uint16) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !h.contains(code) {
+		keys, h.held = append(h.held, code), nil
+		return false, keys
+	}
+	if h.pressed[code] {
+		return false, []uint16{code}
+	}
+	h.pressed[code] = true
+	h.held = append(h.held, code)
+	for _, k := range h.keys {
+		if !h.pressed[k] {
+			return false, nil
+		}
+	}
+	h.held = nil
+	return true, nil
+}
+
+func (h *Hotkey) KeyUp(code uint16) (keys []uint16) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !h.pressed[code] {
+		return nil
+	}
+	delete(h.pressed, code)
+	keys, h.held = h.held, nil
+	return keys
+}
+
+// Flush releases any held-back presses, e.g. when a mouse click shows the
+// chord keys were being used as ordinary modifiers.
+func (h *Hotkey) Flush() (keys []uint16) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	keys, h.held = h.held, nil
+	return keys
+}
+
+// setHotkey rebinds the toggle chord and remembers it, so a binding changed
+// to avoid a game's own doesn't revert on the next launch.
+func (a *APMTracker) setHotkey(combo string) error {
+	if err := a.hotkey.Set(combo); err != nil {
+		return err
+	}
+	a.saveConfig()
+	return nil
+}
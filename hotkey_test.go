@@ -0,0 +1,48 @@
+package main
+
+import (
+	"github.com/robotn/gohook"
+	"slices"
+	"testing"
+)
+
+func TestHotkeyChord(t *testing.T) {
+	ctrl, alt, m, q := hook.Keycode["ctrl"], hook.Keycode["alt"], hook.Keycode["m"], hook.Keycode["q"]
+	h, err := NewHotkey(defaultHotkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A completed chord fires and none of its presses count.
+	for _, code := range []uint16{ctrl, alt} {
+		if fired, keys := h.KeyDown(code); fired || len(keys) != 0 {
+			t.Fatalf("KeyDown(%d) = %v, %v before the chord completed", code, fired, keys)
+		}
+	}
+	if fired, keys := h.KeyDown(m); !fired || len(keys) != 0 {
+		t.Fatalf("KeyDown(m) = %v, %v, want fired with nothing counted", fired, keys)
+	}
+	for _, code := range []uint16{m, alt, ctrl} {
+		if keys := h.KeyUp(code); len(keys) != 0 {
+			t.Fatalf("KeyUp(%d) released %v after the chord fired", code, keys)
+		}
+	}
+
+	// An abandoned chord releases the held presses to be counted.
+	h.KeyDown(ctrl)
+	if fired, keys := h.KeyDown(q); fired || !slices.Equal(keys, []uint16{ctrl, q}) {
+		t.Errorf("KeyDown(q) = %v, %v, want ctrl and q counted", fired, keys)
+	}
+}
+
+func TestHotkeyPersists(t *testing.T) {
+	a, _ := newTestTracker(t)
+	if err := a.setHotkey("ctrl+shift+f9"); err != nil {
+		t.Fatal(err)
+	}
+	b := NewAPMTracker()
+	b.loadConfig()
+	if got := b.hotkey.String(); got != "ctrl+shift+f9" {
+		t.Errorf("hotkey after reload = %q, want ctrl+shift+f9", got)
+	}
+}
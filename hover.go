@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+	"time"
+)
+
+// graphFrame records what the timeline last drew, so hovering can map a
+// pixel back to the bucket under it.
+type graphFrame struct {
+	buckets []int
+	width   int
+	stride  int
+	end     time.Time
+	bucket  time.Duration
+}
+
+// bucketAt inverts the renderer's layout, where bucket i starts at
+// x = width - (i+1)*stride.
+func (f graphFrame) bucketAt(x int) (int, bool) {
+	if f.stride == 0 || x < 0 || x >= f.width {
+		return 0, false
+	}
+	i := (f.width - 1 - x) / f.stride
+	return i, i < len(f.buckets)
+}
+
+// hoverImage reports the image pixel under the pointer. canvas.Image has no
+// input events of its own.
+type hoverImage struct {
+	widget.BaseWidget
+	image   *canvas.Image
+	onHover func(x, y int)
+	onExit  func()
+}
+
+func newHoverImage(img *canvas.Image) *hoverImage {
+	h := &hoverImage{image: img}
+	h.ExtendBaseWidget(h)
+	return h
+}
+
+func (h *hoverImage) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(h.image)
+}
+
+func (h *hoverImage) MouseIn(e *desktop.MouseEvent) {
+	h.MouseMoved(e)
+}
+
+// MouseMoved undoes the fit the painter applies to ImageFillOriginal: the
+// image keeps its aspect and is centered, with padding on one axis.
+func (h *hoverImage) MouseMoved(e *desktop.MouseEvent) {
+	img := h.image.Image
+	if img == nil || h.onHover == nil {
+		return
+	}
+	bounds, size := img.Bounds(), h.Size()
+	aspect := float32(bounds.Dx()) / float32(bounds.Dy())
+	width, height := size.Width, size.Height
+	if size.Width/size.Height > aspect {
+		width = size.Height * aspect
+	} else {
+		height = size.Width / aspect
+	}
+	x := (e.Position.X - (size.Width-width)/2) / width * float32(bounds.Dx())
+	y := (e.Position.Y - (size.Height-height)/2) / height * float32(bounds.Dy())
+	h.onHover(int(x), int(y))
+}
+
+func (h *hoverImage) MouseOut() {
+	if h.onExit != nil {
+		h.onExit()
+	}
+}
+
+func (a *APMTracker) setGraphFrame(f graphFrame) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.lastGraph = f
+}
+
+func (a *APMTracker) hoverGraph(x, _ int) {
+	a.mutex.Lock()
+	f := a.lastGraph
+	a.mutex.Unlock()
+	i, ok := f.bucketAt(x)
+	if !ok {
+		a.graphHoverVar.Set("")
+		return
+	}
+	start := f.end.Add(-time.Duration(i+1) * f.bucket)
+	a.graphHoverVar.Set(fmt.Sprintf("%d actions (%s)", f.buckets[i], start.Format("15:04:05")))
+}
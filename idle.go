@@ -0,0 +1,67 @@
+package main
+
+import "time"
+
+const defaultIdleTimeout = 2 * time.Minute
+
+var idleTimeouts = []time.Duration{
+	30 * time.Second,
+	time.Minute,
+	2 * time.Minute,
+	5 * time.Minute,
+	10 * time.Minute,
+}
+
+func (a *APMTracker) getIdlePause() (enabled bool, timeout time.Duration) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.idlePause, a.idleTimeout
+}
+
+func (a *APMTracker) setIdlePause(enabled bool, timeout time.Duration) {
+	a.mutex.Lock()
+	a.idlePause = enabled
+	if !enabled {
+		a.resumeFromIdle(a.now())
+	}
+	if timeout > 0 {
+		a.idleTimeout = timeout
+	}
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+func (a *APMTracker) isIdle() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.idle
+}
+
+// checkIdle goes idle once no action has arrived for the idle timeout. Idle
+// time is accounted like a pause starting at the last action, so the wait
+// before going idle is excluded from the average too. addAction ends it.
+func (a *APMTracker) checkIdle() {
+	now := a.now()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if !a.idlePause || a.paused || a.idle {
+		return
+	}
+	last := a.lastAction
+	if last.Before(a.startTime) {
+		last = a.startTime
+	}
+	if now.Sub(last) >= a.idleTimeout {
+		a.idle = true
+		a.pausedAt = last
+	}
+}
+
+// resumeFromIdle must be called with a.mutex held.
+func (a *APMTracker) resumeFromIdle(now time.Time) {
+	if a.idle {
+		a.pausedTotal += now.Sub(a.pausedAt)
+		a.idle = false
+		a.activeSince = now
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+// Action is one countable input produced by an InputSource.
+type Action struct {
+	Kind ActionType
+	Code uint16
+}
+
+// InputSource captures input from a device. Start begins capture and returns
+// a channel of countable actions that closes once Stop is called. Sources
+// know nothing of pausing or the clock, so a fake one can drive the tracker
+// with scripted input.
+type InputSource interface {
+	Start() <-chan Action
+	Stop()
+}
+
+// consume counts a source's actions until the source stops.
+func (a *APMTracker) consume(src InputSource) {
+	for action := range src.Start() {
+		if a.onAction(action.Kind, action.Code) && action.Kind == KeyboardAction {
+			a.matchCombo(action.Code)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// scheduled is an action a fake source emits after a pause.
+type scheduled struct {
+	after time.Duration
+	Action
+}
+
+// fakeSource emits its schedule in real time, then closes.
+type fakeSource struct {
+	schedule []scheduled
+}
+
+func (s *fakeSource) Start() <-chan Action {
+	out := make(chan Action)
+	go func() {
+		defer close(out)
+		for _, step := range s.schedule {
+			time.Sleep(step.after)
+			out <- step.Action
+		}
+	}()
+	return out
+}
+
+func (s *fakeSource) Stop() {}
+
+// repeat schedules n copies of action, gap apart.
+func repeat(n int, gap time.Duration, action Action) []scheduled {
+	steps := make([]scheduled, n)
+	for i := range steps {
+		steps[i] = scheduled{gap, action}
+	}
+	return steps
+}
+
+func TestConsumeFakeSource(t *testing.T) {
+	key := Action{KeyboardAction, 30}
+	click := Action{MouseAction, 1}
+	tests := []struct {
+		name                 string
+		schedule             []scheduled
+		mouseCooldown        time.Duration
+		current, keys, mouse int
+	}{
+		{
+			name:     "keys and clicks",
+			schedule: append(repeat(10, 2*time.Millisecond, key), repeat(10, 2*time.Millisecond, click)...),
+			current:  240, keys: 120, mouse: 120,
+		},
+		{
+			// Each double click bounces a millisecond after the real one.
+			name: "double clicks under a cooldown",
+			schedule: append(repeat(5, 0, key),
+				scheduled{100 * time.Millisecond, click}, scheduled{time.Millisecond, click},
+				scheduled{100 * time.Millisecond, click}, scheduled{time.Millisecond, click}),
+			mouseCooldown: 50 * time.Millisecond,
+			current:       84, keys: 60, mouse: 24,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataDir = t.TempDir()
+			a := NewAPMTracker()
+			a.apmWindow = 5 * time.Second
+			a.cooldowns[MouseAction] = tt.mouseCooldown
+			a.consume(&fakeSource{schedule: tt.schedule})
+
+			if got := a.calculateCurrentAPM(); got != tt.current {
+				t.Errorf("current APM = %d, want %d", got, tt.current)
+			}
+			if got := a.calculateKeyboardAPM(); got != tt.keys {
+				t.Errorf("keyboard APM = %d, want %d", got, tt.keys)
+			}
+			if got := a.calculateMouseAPM(); got != tt.mouse {
+				t.Errorf("mouse APM = %d, want %d", got, tt.mouse)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+var modifierKeys = map[uint16]bool{
+	29:   true, // ctrl
+	3613: true, // right ctrl
+	42:   true, // shift
+	54:   true, // right shift
+	56:   true, // alt
+	3640: true, // right alt
+	3675: true, // cmd / super
+	3676: true, // right cmd / super
+}
+
+func (a *APMTracker) getKeyFilters() (excludeMods, ignoreRepeat bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.excludeMods, a.ignoreRepeat
+}
+
+func (a *APMTracker) setKeyFilters(excludeMods, ignoreRepeat bool) {
+	a.mutex.Lock()
+	a.excludeMods = excludeMods
+	a.ignoreRepeat = ignoreRepeat
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+func (a *APMTracker) countsKey(code uint16) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.excludeKeys[code] {
+		return false
+	}
+	return !a.excludeMods || !modifierKeys[code]
+}
+
+// keyPressed reports whether a press is OS auto-repeat that should be
+// ignored: only the first press of a key counts until its release. Held keys
+// are tracked even when the filter is off so toggling it mid-hold is safe.
+// Only the input loop touches heldKeys, so it needs no locking.
+func (a *APMTracker) keyPressed(code uint16) (repeat bool) {
+	_, ignoreRepeat := a.getKeyFilters()
+	repeat = a.heldKeys[code]
+	a.heldKeys[code] = true
+	return repeat && ignoreRepeat
+}
+
+func (a *APMTracker) keyReleased(code uint16) {
+	delete(a.heldKeys, code)
+}
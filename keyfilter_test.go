@@ -0,0 +1,76 @@
+package main
+
+import (
+	"github.com/robotn/gohook"
+	"testing"
+)
+
+func TestAutoRepeat(t *testing.T) {
+	held := []hook.Event{press("w"), press("w"), press("w"), press("w"), press("w")}
+	tests := []struct {
+		name         string
+		ignoreRepeat bool
+		events       []hook.Event
+		want         int64
+	}{
+		{"held key counts once", true, append(held, release("w")), 1},
+		{"released key counts again", true, append(append(held, release("w")), press("w"), release("w")), 2},
+		{"other keys still count while held", true, append(held, press("a"), release("a"), release("w")), 2},
+		{"raw counts when off", false, append(held, release("w")), 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := newTestTracker(t)
+			a.ignoreRepeat = tt.ignoreRepeat
+			feedEvents(a, tt.events...)
+			if got := a.getTotalActions(); got != tt.want {
+				t.Errorf("total actions = %d, want %d", got, tt.want)
+			}
+			if len(a.heldKeys) != 0 {
+				t.Errorf("held keys after release = %v, want none", a.heldKeys)
+			}
+		})
+	}
+}
+
+func TestModifierFilter(t *testing.T) {
+	chord := func(mod, key string) []hook.Event {
+		return []hook.Event{press(mod), press(key), release(key), release(mod)}
+	}
+	tests := []struct {
+		name        string
+		excludeMods bool
+		exclude     string
+		events      []hook.Event
+		want        []string
+	}{
+		{"shift excluded", true, "", chord("shift", "a"), []string{"a"}},
+		{"ctrl excluded", true, "", chord("ctrl", "c"), []string{"c"}},
+		{"lone modifier excluded", true, "", []hook.Event{press("alt"), release("alt")}, nil},
+		{"modifiers counted when off", false, "", chord("shift", "a"), []string{"shift", "a"}},
+		{"excluded key", false, "tab", []hook.Event{press("tab"), release("tab"), press("q"), release("q")}, []string{"q"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := newTestTracker(t)
+			a.excludeMods = tt.excludeMods
+			if tt.exclude != "" {
+				a.excludeKeys[hook.Keycode[tt.exclude]] = true
+			}
+			feedEvents(a, tt.events...)
+			got := a.keyStats.Top(10)
+			if len(got) != len(tt.want) {
+				t.Fatalf("counted %v, want %v", got, tt.want)
+			}
+			for _, name := range tt.want {
+				found := false
+				for _, k := range got {
+					found = found || k.Code == hook.Keycode[name]
+				}
+				if !found {
+					t.Errorf("counted %v, want %s among them", got, name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"github.com/robotn/gohook"
+	"strings"
+)
+
+// hookKeyNames maps keycodes back to gohook's own names, which profiles store
+// because hook.Keycode can parse them again.
+var hookKeyNames = func() map[uint16]string {
+	names := make(map[uint16]string)
+	for name, code := range hook.Keycode {
+		// Shifted aliases such as "_" share a code with their base key.
+		if _, shifted := hook.Special[name]; shifted {
+			continue
+		}
+		if prev, ok := names[code]; !ok || len(name) < len(prev) {
+			names[code] = name
+		}
+	}
+	return names
+}()
+
+// keyDisplayNames overrides gohook names that read poorly in the UI. Keys not
+// listed here fall back to the upper-cased gohook name.
+var keyDisplayNames = map[uint16]string{
+	1:  "Esc",
+	14: "Backspace",
+	15: "Tab",
+	28: "Enter",
+	29: "Ctrl",
+	42: "Shift",
+	54: "Right Shift",
+	56: "Alt",
+	57: "Space",
+
+	3612: "Num Enter",
+	3637: "Num /",
+	3640: "Right Alt",
+	3675: "Cmd",
+	3676: "Right Cmd",
+
+	55: "Num *",
+	74: "Num -",
+	78: "Num +",
+
+	57416: "Up",
+	57419: "Left",
+	57421: "Right",
+	57424: "Down",
+}
+
+func hookKeyName(code uint16) string {
+	return hookKeyNames[code]
+}
+
+func keyName(code uint16) string {
+	if name, ok := keyDisplayNames[code]; ok {
+		return name
+	}
+	name, ok := hookKeyNames[code]
+	if !ok {
+		return fmt.Sprintf("key(0x%02x)", code)
+	}
+	if digit, ok := strings.CutPrefix(name, "num"); ok {
+		return "Num " + digit
+	}
+	return strings.ToUpper(name)
+}
+
+var mouseNames = map[uint16]string{
+	1: "Left Click",
+	2: "Right Click",
+	3: "Middle Click",
+	4: "Mouse 4",
+	5: "Mouse 5",
+
+	wheelCode: "Scroll",
+	dragCode:  "Drag",
+}
+
+func mouseButtonName(button uint16) string {
+	if name, ok := mouseNames[button]; ok {
+		return name
+	}
+	return fmt.Sprintf("button(%d)", button)
+}
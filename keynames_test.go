@@ -0,0 +1,52 @@
+package main
+
+import (
+	"github.com/robotn/gohook"
+	"testing"
+)
+
+func TestKeyNames(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"a", "A"},
+		{"z", "Z"},
+		{"1", "1"},
+		{"space", "Space"},
+		{"enter", "Enter"},
+		{"esc", "Esc"},
+		{"ctrl", "Ctrl"},
+		{"shift", "Shift"},
+		{"rshift", "Right Shift"},
+		{"f5", "F5"},
+		{"f12", "F12"},
+		{"up", "Up"},
+	}
+	for _, tt := range tests {
+		if got := keyName(hook.Keycode[tt.key]); got != tt.want {
+			t.Errorf("keyName(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+	if got := keyName(0xfff0); got != "key(0xfff0)" {
+		t.Errorf("unknown key = %q, want key(0xfff0)", got)
+	}
+}
+
+func TestMouseButtonNames(t *testing.T) {
+	tests := []struct {
+		button uint16
+		want   string
+	}{
+		{1, "Left Click"},
+		{2, "Right Click"},
+		{3, "Middle Click"},
+		{wheelCode, "Scroll"},
+		{9, "button(9)"},
+	}
+	for _, tt := range tests {
+		if got := mouseButtonName(tt.button); got != tt.want {
+			t.Errorf("mouseButtonName(%d) = %q, want %q", tt.button, got, tt.want)
+		}
+	}
+}
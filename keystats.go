@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2/dialog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxTrackedKeys bounds the counter so a flood of unusual keycodes cannot grow
+// it indefinitely; codes first seen after the limit is reached are ignored.
+const maxTrackedKeys = 512
+
+type KeyCounter struct {
+	mutex  sync.Mutex
+	counts map[uint16]int
+}
+
+type KeyCount struct {
+	Code  uint16
+	Count int
+}
+
+func NewKeyCounter() *KeyCounter {
+	return &KeyCounter{counts: make(map[uint16]int)}
+}
+
+func (kc *KeyCounter) Record(code uint16) {
+	kc.mutex.Lock()
+	defer kc.mutex.Unlock()
+
+	if _, ok := kc.counts[code]; !ok && len(kc.counts) >= maxTrackedKeys {
+		return
+	}
+	kc.counts[code]++
+}
+
+func (kc *KeyCounter) Reset() {
+	kc.mutex.Lock()
+	defer kc.mutex.Unlock()
+
+	kc.counts = make(map[uint16]int)
+}
+
+// Top returns up to n codes ordered by descending count.
+func (kc *KeyCounter) Top(n int) []KeyCount {
+	kc.mutex.Lock()
+	result := make([]KeyCount, 0, len(kc.counts))
+	for code, count := range kc.counts {
+		result = append(result, KeyCount{code, count})
+	}
+	kc.mutex.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Code < result[j].Code
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+func (a *APMTracker) showKeyStats() {
+	var b strings.Builder
+	b.WriteString("Keys:\n")
+	keys := a.keyStats.Top(10)
+	if len(keys) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, k := range keys {
+		fmt.Fprintf(&b, "  %-10s %d\n", keyName(k.Code), k.Count)
+	}
+	b.WriteString("\nMouse:\n")
+	buttons := a.mouseStats.Top(10)
+	if len(buttons) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, m := range buttons {
+		fmt.Fprintf(&b, "  %-10s %d\n", mouseButtonName(m.Code), m.Count)
+	}
+	dialog.ShowInformation("Key Stats", b.String(), a.window)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// markerSize is the side of the square dot marking each bucket's top.
+const markerSize = 3
+
+func (a *APMTracker) getGraphMarkers() (dots, current bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.graphDots, a.currentLine
+}
+
+func (a *APMTracker) setGraphMarkers(dots, current bool) {
+	a.mutex.Lock()
+	a.graphDots = dots
+	a.currentLine = current
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.updateGraph()
+}
+
+// dots marks each bucket's top with the same scaling as the bars, clamped so
+// markers at the edges stay whole.
+func (r GraphRenderer) dots(img *image.RGBA, buckets []int, scale float64) {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	fill := image.NewUniform(r.Palette.Axis)
+	for i, count := range buckets {
+		barHeight := min(int(float64(count)/scale*float64(height)), height)
+		x := width - (i+1)*r.stride() + r.BarWidth/2 - markerSize/2
+		y := height - barHeight - markerSize/2
+		x = min(max(x, 0), width-markerSize)
+		y = min(max(y, 0), height-markerSize)
+		dot := image.Rect(x, y, x+markerSize, y+markerSize).Intersect(img.Rect)
+		draw.Draw(img, dot, fill, image.Point{}, draw.Src)
+	}
+}
+
+// currentLine draws a solid line at apm, pinned to the top when the axis is
+// fixed below it.
+func currentLine(img *image.RGBA, apm int, maxAPM float64, c color.Color) {
+	height := img.Rect.Dy()
+	y := height - 1 - int(float64(apm)/maxAPM*float64(height))
+	y = min(max(y, 0), height-1)
+	for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+		setPixel(img, x, y, c)
+	}
+}
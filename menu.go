@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"net/url"
+)
+
+func (a *APMTracker) setupMenu() {
+	quit := fyne.NewMenuItem("Quit", func() {
+		a.onClosing()
+	})
+	quit.IsQuit = true
+	file := fyne.NewMenu("File",
+		fyne.NewMenuItem("Export CSV…", func() {
+			a.showExportCSVDialog()
+		}),
+		fyne.NewMenuItem("Export JSON…", func() {
+			a.showExportJSONDialog()
+		}),
+		fyne.NewMenuItem("Export Session…", func() {
+			a.showExportSessionDialog()
+		}),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Start Recording…", func() {
+			a.showRecordingDialog()
+		}),
+		fyne.NewMenuItem("Stop Recording", func() {
+			if err := a.StopRecording(); err != nil {
+				dialog.ShowError(err, a.window)
+			}
+		}),
+		fyne.NewMenuItemSeparator(),
+		quit,
+	)
+
+	edit := fyne.NewMenu("Edit",
+		fyne.NewMenuItem("Copy Stats", func() {
+			a.copyStats()
+		}),
+	)
+
+	themes := fyne.NewMenuItem("Theme", nil)
+	themes.ChildMenu = fyne.NewMenu("",
+		fyne.NewMenuItem("Light", func() {
+			a.setTheme(lightTheme)
+		}),
+		fyne.NewMenuItem("Dark", func() {
+			a.setTheme(darkTheme)
+		}),
+	)
+	modes := fyne.NewMenuItem("Graph Mode", nil)
+	modes.ChildMenu = fyne.NewMenu("",
+		fyne.NewMenuItem("Bar", func() {
+			a.setGraphMode(BarGraph)
+		}),
+		fyne.NewMenuItem("Line", func() {
+			a.setGraphMode(LineGraph)
+		}),
+	)
+	compare := fyne.NewMenuItem("Compare With", nil)
+	compare.ChildMenu = fyne.NewMenu("",
+		fyne.NewMenuItem("Last Session", func() {
+			a.compareLastSession()
+		}),
+		fyne.NewMenuItem("Session File…", func() {
+			a.showCompareDialog()
+		}),
+		fyne.NewMenuItem("Nothing", func() {
+			a.setComparison(nil)
+		}),
+	)
+	view := fyne.NewMenu("View",
+		fyne.NewMenuItem("Toggle Mini View", func() {
+			a.toggleView()
+		}),
+		fyne.NewMenuItem("Toggle Stream Overlay", func() {
+			a.toggleOverlay()
+		}),
+		fyne.NewMenuItem("Freeze Display", func() {
+			a.toggleFreeze()
+		}),
+		fyne.NewMenuItem("History", func() {
+			a.showHistory()
+		}),
+		fyne.NewMenuItemSeparator(),
+		themes,
+		modes,
+		compare,
+	)
+
+	help := fyne.NewMenu("Help",
+		fyne.NewMenuItem("Input Log", func() {
+			a.showInputLog()
+		}),
+		fyne.NewMenuItem("About", func() {
+			a.showAbout()
+		}),
+	)
+
+	a.window.SetMainMenu(fyne.NewMainMenu(file, edit, view, help))
+}
+
+func (a *APMTracker) showExportJSONDialog() {
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+		if err := a.writeStatsJSON(writer); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	}, a.window)
+	save.SetFileName("apm-stats.json")
+	save.Show()
+}
+
+// showExportSessionDialog saves the raw session, timestamps included, in the
+// same format as the session file restored on startup.
+func (a *APMTracker) showExportSessionDialog() {
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+		enc := json.NewEncoder(writer)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(a.snapshotSession()); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	}, a.window)
+	save.SetFileName("apm-session.json")
+	save.Show()
+}
+
+func (a *APMTracker) showAbout() {
+	repo, _ := url.Parse(repoURL)
+	issues, _ := url.Parse(repoURL + "/issues")
+	content := container.NewVBox(
+		widget.NewLabel("Measures actions per minute from keyboard and mouse input."),
+		widget.NewLabel(fmt.Sprintf("Version: %s\nCommit: %s\nBuilt: %s", version, commit, buildDate)),
+		widget.NewHyperlink("Source code", repo),
+		widget.NewHyperlink("Report a bug", issues),
+	)
+	dialog.ShowCustom("About APM Tracker", "Close", content, a.window)
+}
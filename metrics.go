@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+const defaultMetricsAddr = "localhost:9099"
+
+func (a *APMTracker) startMetricsServer(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", a.serveMetrics)
+	a.metricsServer = &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		err := a.metricsServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("metrics server: %v", err)
+		}
+	}()
+}
+
+func (a *APMTracker) stopMetricsServer() {
+	if a.metricsServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := a.metricsServer.Shutdown(ctx); err != nil {
+		log.Printf("metrics server shutdown: %v", err)
+	}
+}
+
+func (a *APMTracker) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	stats := a.latestStats()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	writeGauge(w, "current_apm", "Actions in the current window, scaled to a per-minute rate.", float64(stats.Current))
+	writeGauge(w, "peak_apm", "Highest current APM seen this session.", float64(stats.Peak))
+	writeGauge(w, "average_apm", "Actions per minute averaged over the active session.", stats.Average)
+	writeGauge(w, "total_actions", "Actions recorded this session.", float64(stats.TotalActions))
+}
+
+func writeGauge(w http.ResponseWriter, name, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+}
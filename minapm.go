@@ -0,0 +1,55 @@
+package main
+
+import "time"
+
+const defaultWarmUp = 10 * time.Second
+
+var warmUps = []time.Duration{
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+func (a *APMTracker) getWarmUp() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.warmUp
+}
+
+func (a *APMTracker) setWarmUp(d time.Duration) {
+	a.mutex.Lock()
+	a.warmUp = d
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+// updateMinAPM folds the current APM into the session minimum. Counting has
+// to have run uninterrupted for the warm-up period first: right after a
+// start, reset, pause or idle stretch the APM window is still part empty and
+// would latch the minimum to near zero.
+func (a *APMTracker) updateMinAPM(stats Stats) {
+	now := a.now()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	since := a.activeSince
+	if since.Before(a.startTime) {
+		since = a.startTime
+	}
+	if stats.Paused || stats.Idle || now.Sub(since) < a.warmUp {
+		return
+	}
+	if !a.minAPMSet || stats.Current < a.minAPM {
+		a.minAPM, a.minAPMSet = stats.Current, true
+	}
+}
+
+// getMinAPM returns -1 until a minimum has been recorded.
+func (a *APMTracker) getMinAPM() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if !a.minAPMSet {
+		return -1
+	}
+	return a.minAPM
+}
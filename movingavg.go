@@ -0,0 +1,59 @@
+package main
+
+import (
+	"image"
+	"time"
+)
+
+const defaultMAWindow = 5 * time.Second
+
+var maWindows = []time.Duration{
+	3 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+}
+
+func (a *APMTracker) getMovingAvg() (bool, time.Duration) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.maLine, a.maWindow
+}
+
+func (a *APMTracker) setMovingAvg(on bool, window time.Duration) {
+	a.mutex.Lock()
+	a.maLine = on
+	a.maWindow = window
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.updateGraph()
+}
+
+// movingAverage smooths buckets (newest first) over the trailing n buckets.
+// The oldest few average over what they have.
+func movingAverage(buckets []int, n int) []float64 {
+	avg := make([]float64, len(buckets))
+	sum := 0
+	for i := len(buckets) - 1; i >= 0; i-- {
+		sum += buckets[i]
+		if i+n < len(buckets) {
+			sum -= buckets[i+n]
+		}
+		avg[i] = float64(sum) / float64(min(n, len(buckets)-i))
+	}
+	return avg
+}
+
+// maLine draws the moving average over the bars with the same scaling.
+func (r GraphRenderer) maLine(img *image.RGBA, buckets []int, scale float64) {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	prevX, prevY := 0, 0
+	for i, v := range movingAverage(buckets, r.MovingAvg) {
+		x := width - (i+1)*r.stride() + r.BarWidth/2
+		y := height - 1 - min(int(v/scale*float64(height-1)), height-1)
+		if i > 0 {
+			drawLine(img, prevX, prevY, x, y, r.Palette.Average)
+		}
+		prevX, prevY = x, y
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+const defaultAvgDecimals = 2
+
+// thousandsSeps are the grouping choices offered in settings. Grouping with
+// a period switches the decimal mark to a comma, as in most of Europe.
+var thousandsSeps = []string{"", ",", ".", " "}
+
+// numberFormat is a snapshot of the display settings, taken once per label
+// refresh.
+type numberFormat struct {
+	decimals int
+	sep      string
+}
+
+func (a *APMTracker) getNumberFormat() numberFormat {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return numberFormat{a.avgDecimals, a.thousandsSep}
+}
+
+func (a *APMTracker) setNumberFormat(decimals int, sep string) {
+	a.mutex.Lock()
+	a.avgDecimals = min(max(decimals, 0), 3)
+	a.thousandsSep = sep
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.refreshLabels()
+}
+
+func (f numberFormat) count(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	if f.sep == "" {
+		return s
+	}
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	var b strings.Builder
+	for i, digit := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteString(f.sep)
+		}
+		b.WriteRune(digit)
+	}
+	return sign + b.String()
+}
+
+func (f numberFormat) average(v float64) string {
+	s := strconv.FormatFloat(v, 'f', f.decimals, 64)
+	whole, frac, _ := strings.Cut(s, ".")
+	n, _ := strconv.ParseInt(whole, 10, 64)
+	whole = f.count(n)
+	if frac == "" {
+		return whole
+	}
+	if f.sep == "." {
+		return whole + "," + frac
+	}
+	return whole + "." + frac
+}
+
+func formatThousandsSep(sep string) string {
+	switch sep {
+	case "":
+		return "None"
+	case " ":
+		return "1 000"
+	default:
+		return "1" + sep + "000"
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"image/color"
+	"strconv"
+)
+
+const (
+	defaultOverlaySize = 72
+	minOverlaySize     = 24
+	maxOverlaySize     = 240
+	// overlayWidest is measured to size the window, so four-digit APM fits.
+	overlayWidest = "8888"
+)
+
+func (a *APMTracker) getOverlayStyle() (size float32, background float64) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.overlaySize, a.overlayAlpha
+}
+
+func (a *APMTracker) setOverlayStyle(size float32, background float64) {
+	a.mutex.Lock()
+	a.overlaySize = min(max(size, minOverlaySize), maxOverlaySize)
+	a.overlayAlpha = min(max(background, 0), 1)
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.styleOverlay()
+}
+
+// toggleOverlay opens or closes the stream overlay: a borderless window with
+// nothing but the current APM in large type, for capturing in OBS and the
+// like. A background opacity of zero leaves only the number, for platforms
+// that composite transparent windows; elsewhere a solid background can be
+// keyed out.
+func (a *APMTracker) toggleOverlay() {
+	if a.overlayWindow != nil {
+		a.overlayWindow.Close()
+		return
+	}
+
+	var w fyne.Window
+	if drv, ok := a.app.Driver().(desktop.Driver); ok {
+		w = drv.CreateSplashWindow()
+	} else {
+		w = a.app.NewWindow("APM Overlay")
+	}
+	a.overlayText = canvas.NewText("0", color.White)
+	a.overlayText.Alignment = fyne.TextAlignCenter
+	a.overlayText.TextStyle = fyne.TextStyle{Bold: true}
+	a.overlayBg = canvas.NewRectangle(color.Transparent)
+	area := newDragArea(container.NewStack(a.overlayBg, container.NewCenter(a.overlayText)))
+	area.onDragged = func(dx, dy float32) {
+		scale := w.Canvas().Scale()
+		runNative(w, func(context any) {
+			if x, y, ok := getNativePosition(context); ok {
+				setNativePosition(context, x+int(dx*scale), y+int(dy*scale))
+			}
+		})
+	}
+	w.SetContent(area)
+	w.SetFixedSize(true)
+
+	// Follow the same binding as the main window so the overlay updates on
+	// the same tick.
+	listener := binding.NewDataListener(func() {
+		a.overlayText.Text = strconv.Itoa(a.latestStats().Current)
+		a.overlayText.Refresh()
+	})
+	a.currentAPMVar.AddListener(listener)
+	w.SetOnClosed(func() {
+		a.currentAPMVar.RemoveListener(listener)
+		a.overlayWindow, a.overlayText, a.overlayBg = nil, nil, nil
+	})
+
+	a.overlayWindow = w
+	a.styleOverlay()
+	w.Show()
+	setClickThrough(w, a.isClickThrough())
+}
+
+func (a *APMTracker) styleOverlay() {
+	if a.overlayWindow == nil {
+		return
+	}
+	size, background := a.getOverlayStyle()
+	settings := a.app.Settings()
+	a.overlayText.Color = settings.Theme().Color(theme.ColorNameForeground, settings.ThemeVariant())
+	a.overlayText.TextSize = size
+	a.overlayText.Refresh()
+
+	r, g, b, _ := a.getPalette().Background.RGBA()
+	a.overlayBg.FillColor = color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(background * 0xff)}
+	a.overlayBg.Refresh()
+
+	text := fyne.MeasureText(overlayWidest, size, a.overlayText.TextStyle)
+	pad := size / 4
+	a.overlayWindow.Resize(fyne.NewSize(text.Width+2*pad, text.Height+pad))
+}
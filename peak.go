@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"time"
+)
+
+// peakNotifyInterval debounces notifications while the peak keeps climbing.
+const peakNotifyInterval = 5 * time.Second
+
+func (a *APMTracker) isPeakNotify() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.peakNotify
+}
+
+func (a *APMTracker) setPeakNotify(on bool) {
+	a.mutex.Lock()
+	a.peakNotify = on
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+// checkNewPeak notifies when the session peak has risen since the last
+// notification. The first peak seen only sets the baseline, so the climb from
+// zero at the start of a session stays quiet.
+func (a *APMTracker) checkNewPeak(stats Stats) {
+	now := a.now()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.notifiedPeak == 0 {
+		a.notifiedPeak = stats.Peak
+		return
+	}
+	if !a.peakNotify || stats.Peak <= a.notifiedPeak || now.Sub(a.peakNotifiedAt) < peakNotifyInterval {
+		return
+	}
+	a.notifiedPeak, a.peakNotifiedAt = stats.Peak, now
+	a.app.SendNotification(fyne.NewNotification("APM Tracker", fmt.Sprintf("New peak APM: %d!", stats.Peak)))
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"image"
+)
+
+const (
+	cornerTopLeft     = "top-left"
+	cornerTopRight    = "top-right"
+	cornerBottomLeft  = "bottom-left"
+	cornerBottomRight = "bottom-right"
+
+	cornerMargin = 16
+)
+
+var miniCorners = []string{cornerTopLeft, cornerTopRight, cornerBottomLeft, cornerBottomRight}
+
+type monitorArea struct {
+	name                string
+	x, y, width, height int
+}
+
+// listMonitors must run on the main thread, which RunNative guarantees.
+func listMonitors() []monitorArea {
+	var areas []monitorArea
+	for _, m := range glfw.GetMonitors() {
+		x, y, w, h := m.GetWorkarea()
+		areas = append(areas, monitorArea{m.GetName(), x, y, w, h})
+	}
+	return areas
+}
+
+// findMonitor returns the named monitor's work area, falling back to the
+// primary display when it has been disconnected so the window never ends up
+// off-screen.
+func findMonitor(name string) (monitorArea, bool) {
+	for _, area := range listMonitors() {
+		if area.name == name {
+			return area, true
+		}
+	}
+	if primary := glfw.GetPrimaryMonitor(); primary != nil {
+		x, y, w, h := primary.GetWorkarea()
+		return monitorArea{primary.GetName(), x, y, w, h}, true
+	}
+	return monitorArea{}, false
+}
+
+func cornerPosition(area monitorArea, corner string, w, h int) (x, y int) {
+	x, y = area.x+cornerMargin, area.y+cornerMargin
+	switch corner {
+	case cornerTopRight:
+		x = area.x + area.width - w - cornerMargin
+	case cornerBottomLeft:
+		y = area.y + area.height - h - cornerMargin
+	case cornerBottomRight:
+		x = area.x + area.width - w - cornerMargin
+		y = area.y + area.height - h - cornerMargin
+	}
+	return x, y
+}
+
+func runNative(w fyne.Window, fn func(context any)) {
+	if nw, ok := w.(driver.NativeWindow); ok {
+		nw.RunNative(fn)
+	}
+}
+
+// monitorNames lists the connected displays using the main window's native
+// context to reach the main thread.
+func (a *APMTracker) monitorNames() []string {
+	var names []string
+	runNative(a.window, func(any) {
+		for _, area := range listMonitors() {
+			names = append(names, area.name)
+		}
+	})
+	return names
+}
+
+func (a *APMTracker) getMiniPlacement() (monitor, corner string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.miniMonitor, a.miniCorner
+}
+
+func (a *APMTracker) setMiniPlacement(monitor, corner string) {
+	a.mutex.Lock()
+	a.miniMonitor, a.miniCorner = monitor, corner
+	a.miniCustomPos = false
+	a.mutex.Unlock()
+	a.saveConfig()
+	if a.isMiniView() {
+		a.positionMiniView()
+	}
+}
+
+func (a *APMTracker) miniPixelSize() (w, h int) {
+	c := a.miniWindow.Canvas()
+	return int(c.Size().Width * c.Scale()), int(c.Size().Height * c.Scale())
+}
+
+func (a *APMTracker) positionMiniView() {
+	a.mutex.Lock()
+	monitor, corner := a.miniMonitor, a.miniCorner
+	pos, custom := a.miniPos, a.miniCustomPos
+	a.mutex.Unlock()
+
+	w, h := a.miniPixelSize()
+	runNative(a.miniWindow, func(context any) {
+		area, ok := findMonitor(monitor)
+		if !ok {
+			return
+		}
+		x, y := cornerPosition(area, corner, w, h)
+		// A dragged position is only honoured while it is still on a
+		// connected display.
+		if custom && onAnyMonitor(pos) {
+			x, y = pos.X, pos.Y
+		}
+		setNativePosition(context, x, y)
+		a.setMiniRect(image.Rect(x, y, x+w, y+h))
+	})
+}
+
+func onAnyMonitor(p image.Point) bool {
+	for _, area := range listMonitors() {
+		if p.In(image.Rect(area.x, area.y, area.x+area.width, area.y+area.height)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *APMTracker) setMiniRect(r image.Rectangle) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.miniRect = r
+}
+
+// insideMiniView reports clicks that grab the mini view. With click-through
+// on, they land in the game underneath instead.
+func (a *APMTracker) insideMiniView(x, y int) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return !a.clickThrough && image.Pt(x, y).In(a.miniRect)
+}
+
+func (a *APMTracker) dragMiniView(dx, dy float32) {
+	scale := a.miniWindow.Canvas().Scale()
+	w, h := a.miniPixelSize()
+	runNative(a.miniWindow, func(context any) {
+		x, y, ok := getNativePosition(context)
+		if !ok {
+			return
+		}
+		x += int(dx * scale)
+		y += int(dy * scale)
+		setNativePosition(context, x, y)
+		a.setMiniRect(image.Rect(x, y, x+w, y+h))
+	})
+}
+
+func (a *APMTracker) endMiniDrag() {
+	a.mutex.Lock()
+	a.miniPos = a.miniRect.Min
+	a.miniCustomPos = true
+	a.mutex.Unlock()
+	a.saveConfig()
+}
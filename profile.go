@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/robotn/gohook"
+	"io/fs"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultProfile = "Default"
+
+// Profile bundles the settings that usually differ between games.
+type Profile struct {
+	// Executable is a glob matched against the foreground process name when
+	// switching profiles automatically, e.g. "sc2*.exe".
+	Executable     string          `json:"executable,omitempty"`
+	APMWindow      int             `json:"apm_window_seconds,omitempty"`
+	TargetAPM      int             `json:"target_apm,omitempty"`
+	CountEvents    map[string]bool `json:"count_events,omitempty"`
+	CountModifiers bool            `json:"count_modifiers,omitempty"`
+	IgnoreRepeat   bool            `json:"ignore_repeat,omitempty"`
+	ExcludeKeys    []string        `json:"exclude_keys,omitempty"`
+}
+
+func defaultProfileSettings() Profile {
+	return Profile{APMWindow: int(time.Minute.Seconds())}
+}
+
+func profileDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "profiles"), nil
+}
+
+func validateProfileName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\:`) {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	return nil
+}
+
+func profilePath(name string) (string, error) {
+	if err := validateProfileName(name); err != nil {
+		return "", err
+	}
+	dir, err := profileDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+func loadProfile(path string) (Profile, error) {
+	var p Profile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	err = json.Unmarshal(data, &p)
+	return p, err
+}
+
+func saveProfile(path string, p Profile) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// listProfiles returns the saved profile names, always including the default.
+func listProfiles() []string {
+	names := []string{defaultProfile}
+	dir, err := profileDir()
+	if err != nil {
+		return names
+	}
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names[1:])
+	return names
+}
+
+func (a *APMTracker) currentProfile() Profile {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	p := Profile{
+		Executable:     a.profileExe,
+		APMWindow:      int(a.apmWindow.Seconds()),
+		TargetAPM:      a.targetAPM,
+		CountEvents:    maps.Clone(a.countEvents),
+		CountModifiers: !a.excludeMods,
+		IgnoreRepeat:   a.ignoreRepeat,
+	}
+	for code := range a.excludeKeys {
+		p.ExcludeKeys = append(p.ExcludeKeys, hookKeyName(code))
+	}
+	slices.Sort(p.ExcludeKeys)
+	return p
+}
+
+func (a *APMTracker) applyProfile(p Profile) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.profileExe = p.Executable
+	a.apmWindow = time.Minute
+	if p.APMWindow > 0 {
+		a.apmWindow = time.Duration(p.APMWindow) * time.Second
+	}
+	a.targetAPM = max(p.TargetAPM, 0)
+	clear(a.countEvents)
+	for name, on := range p.CountEvents {
+		if on {
+			a.countEvents[name] = true
+		}
+	}
+	a.excludeMods = !p.CountModifiers
+	a.ignoreRepeat = p.IgnoreRepeat
+	clear(a.excludeKeys)
+	for _, name := range p.ExcludeKeys {
+		if code, ok := hook.Keycode[strings.ToLower(name)]; ok {
+			a.excludeKeys[code] = true
+		} else {
+			log.Printf("warning: unknown key %q in profile", name)
+		}
+	}
+}
+
+// readProfile loads the named profile, falling back to the defaults. A missing
+// default profile is expected and not worth a warning.
+func readProfile(name string) Profile {
+	path, err := profilePath(name)
+	if err != nil {
+		log.Printf("warning: using default settings: %v", err)
+		return defaultProfileSettings()
+	}
+	p, err := loadProfile(path)
+	if errors.Is(err, fs.ErrNotExist) && name == defaultProfile {
+		return defaultProfileSettings()
+	}
+	if err != nil {
+		log.Printf("warning: using default settings for profile %s: %v", name, err)
+		return defaultProfileSettings()
+	}
+	return p
+}
+
+func (a *APMTracker) getProfile() string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.profile
+}
+
+// switchProfile applies the named profile live and makes it the active one.
+func (a *APMTracker) switchProfile(name string) {
+	a.applyProfile(readProfile(name))
+	a.mutex.Lock()
+	a.profile = name
+	a.mutex.Unlock()
+	a.writeConfig()
+	if a.window != nil {
+		a.profileSelect.SetSelected(name)
+		a.refresh()
+	}
+}
+
+func (a *APMTracker) saveProfile() {
+	path, err := profilePath(a.getProfile())
+	if err == nil {
+		err = saveProfile(path, a.currentProfile())
+	}
+	if err != nil {
+		log.Printf("failed to save profile: %v", err)
+	}
+}
+
+func (a *APMTracker) newProfileSelect() *widget.Select {
+	sel := widget.NewSelect(listProfiles(), nil)
+	sel.SetSelected(a.getProfile())
+	sel.OnChanged = func(name string) {
+		if name != a.getProfile() {
+			a.switchProfile(name)
+		}
+	}
+	return sel
+}
+
+// showNewProfileDialog saves the current settings under a new name and
+// switches to it.
+func (a *APMTracker) showNewProfileDialog(sel *widget.Select) {
+	entry := widget.NewEntry()
+	entry.Validator = validateProfileName
+	dialog.ShowForm("New profile", "Create", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Name", entry)},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			name := strings.TrimSpace(entry.Text)
+			a.mutex.Lock()
+			a.profile = name
+			a.mutex.Unlock()
+			a.saveConfig()
+			sel.Options = listProfiles()
+			sel.SetSelected(name)
+		}, a.window)
+}
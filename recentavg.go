@@ -0,0 +1,43 @@
+package main
+
+import "time"
+
+const defaultRecentAvgWindow = 10 * time.Minute
+
+var recentAvgWindows = []time.Duration{
+	5 * time.Minute,
+	10 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+}
+
+func (a *APMTracker) getRecentAvgWindow() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.recentAvgWin
+}
+
+func (a *APMTracker) setRecentAvgWindow(d time.Duration) {
+	a.mutex.Lock()
+	a.recentAvgWin = d
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+// calculateRecentAverage averages the per-tick APM samples covering the
+// recent window. No samples are taken while paused or idle, so those stretches
+// drop out rather than dragging the average down.
+func (a *APMTracker) calculateRecentAverage() float64 {
+	window, interval := a.getRecentAvgWindow(), a.getUpdateInterval()
+	limit := int(window / interval)
+	sum, n := 0, 0
+	a.apmSamples.ForEachNewest(func(s int) bool {
+		sum += s
+		n++
+		return n < limit
+	})
+	if n == 0 {
+		return 0
+	}
+	return float64(sum) / float64(n)
+}
@@ -0,0 +1,40 @@
+package main
+
+import "time"
+
+const defaultRecentPeakWindow = 5 * time.Minute
+
+var recentPeakWindows = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	10 * time.Minute,
+	30 * time.Minute,
+}
+
+func (a *APMTracker) getRecentPeakWindow() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.recentWindow
+}
+
+func (a *APMTracker) setRecentPeakWindow(d time.Duration) {
+	a.mutex.Lock()
+	a.recentWindow = d
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+// calculateRecentPeak scans the per-tick APM samples covering the recent
+// window. Samples are taken once per update, so the window is converted to a
+// sample count at the current update interval.
+func (a *APMTracker) calculateRecentPeak() int {
+	window, interval := a.getRecentPeakWindow(), a.getUpdateInterval()
+	n := int(window / interval)
+	peak := 0
+	a.apmSamples.ForEachNewest(func(s int) bool {
+		peak = max(peak, s)
+		n--
+		return n > 0
+	})
+	return peak
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recordings hold one action per line as "unix_nanos,kind,code", where kind
+// is the ActionType and code the key or mouse button.
+type recordedAction struct {
+	At   int64
+	Kind ActionType
+	Code uint16
+}
+
+func (a *APMTracker) StartRecording(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.recorder != nil {
+		f.Close()
+		return errors.New("already recording")
+	}
+	a.recordFile, a.recorder = f, bufio.NewWriter(f)
+	return nil
+}
+
+func (a *APMTracker) StopRecording() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.recorder == nil {
+		return nil
+	}
+	err := a.recorder.Flush()
+	if closeErr := a.recordFile.Close(); err == nil {
+		err = closeErr
+	}
+	a.recordFile, a.recorder = nil, nil
+	return err
+}
+
+func (a *APMTracker) isRecording() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.recorder != nil
+}
+
+func loadRecording(path string) ([]recordedAction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var actions []recordedAction
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), ",")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected 3 fields, got %d", path, line, len(fields))
+		}
+		at, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		kind, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		code, err := strconv.ParseUint(fields[2], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		actions = append(actions, recordedAction{At: at, Kind: ActionType(kind), Code: uint16(code)})
+	}
+	return actions, scanner.Err()
+}
+
+// loadReplay prepares the tracker to play back a recording instead of
+// capturing input. It must run before the GUI starts, as it swaps the
+// tracker's clock for one that runs from the first recorded action at speed
+// times real time.
+func (a *APMTracker) loadReplay(path string, speed float64) error {
+	actions, err := loadRecording(path)
+	if err != nil {
+		return err
+	}
+	if len(actions) == 0 {
+		return fmt.Errorf("%s: no actions recorded", path)
+	}
+	if speed <= 0 {
+		return fmt.Errorf("invalid replay speed %v", speed)
+	}
+	origin, started := time.Unix(0, actions[0].At), time.Now()
+	a.replay, a.replaySpeed = actions, speed
+	a.startTime = origin
+	a.now = func() time.Time {
+		return origin.Add(time.Duration(float64(time.Since(started)) * speed))
+	}
+	return nil
+}
+
+func (a *APMTracker) runReplay() {
+	for _, action := range a.replay {
+		wait := time.Duration(float64(action.At-a.now().UnixNano()) / a.replaySpeed)
+		if wait > 0 {
+			time.Sleep(wait)
+		}
+		if !a.isRunning() {
+			return
+		}
+		a.addAction(action.Kind, action.Code)
+	}
+}
+
+func (a *APMTracker) showRecordingDialog() {
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		writer.Close()
+		if err := a.StartRecording(writer.URI().Path()); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	}, a.window)
+	save.SetFileName("apm-recording.txt")
+	save.Show()
+}
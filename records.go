@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// recordAvgWindow is the span of the best-average record.
+	recordAvgWindow = 5 * time.Minute
+	// recordSaveInterval bounds how often a climbing record is written out.
+	recordSaveInterval = 30 * time.Second
+)
+
+// Records are lifetime bests, kept apart from the session so that neither a
+// reset nor an expired resume window can lose them.
+type Records struct {
+	PeakAPM     int       `json:"peak_apm"`
+	PeakAPMTime time.Time `json:"peak_apm_time"`
+	BestAvg     float64   `json:"best_5min_average"`
+	BestAvgTime time.Time `json:"best_5min_average_time"`
+}
+
+func recordsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "records.json"), nil
+}
+
+func (a *APMTracker) loadRecords() {
+	path, err := recordsPath()
+	if err != nil {
+		log.Printf("warning: failed to locate records file: %v", err)
+		return
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	var records Records
+	if err == nil {
+		err = json.Unmarshal(data, &records)
+	}
+	if err != nil {
+		log.Printf("warning: ignoring unreadable records %s: %v", path, err)
+		return
+	}
+	a.mutex.Lock()
+	a.records = records
+	a.mutex.Unlock()
+}
+
+func (a *APMTracker) saveRecords() error {
+	path, err := recordsPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(a.getRecords(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (a *APMTracker) getRecords() Records {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.records
+}
+
+func (a *APMTracker) isRecordNotify() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.recordNotify
+}
+
+func (a *APMTracker) setRecordNotify(on bool) {
+	a.mutex.Lock()
+	a.recordNotify = on
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+// updateRecords raises the lifetime bests from this session. The average only
+// counts once a full window has run without a pause or idle stretch.
+func (a *APMTracker) updateRecords(stats Stats) {
+	if a.isSimulated() || stats.Paused || stats.Idle {
+		return
+	}
+	now := a.now()
+	recent := countWithin(a.actions, now, recordAvgWindow)
+
+	a.mutex.Lock()
+	since := a.activeSince
+	if since.Before(a.startTime) {
+		since = a.startTime
+	}
+	var broken []string
+	if stats.Peak > a.records.PeakAPM {
+		if a.records.PeakAPM > 0 {
+			broken = append(broken, fmt.Sprintf("APM %d", stats.Peak))
+		}
+		a.records.PeakAPM, a.records.PeakAPMTime = stats.Peak, stats.PeakTime
+		a.recordsDirty = true
+	}
+	if avg := float64(recent) / recordAvgWindow.Minutes(); now.Sub(since) >= recordAvgWindow && avg > a.records.BestAvg {
+		if a.records.BestAvg > 0 {
+			broken = append(broken, fmt.Sprintf("5 min average %.1f", avg))
+		}
+		a.records.BestAvg, a.records.BestAvgTime = avg, now
+		a.recordsDirty = true
+	}
+	save := a.recordsDirty && now.Sub(a.recordsSaved) >= recordSaveInterval
+	if save {
+		a.recordsDirty, a.recordsSaved = false, now
+	}
+	notify := len(broken) > 0 && a.recordNotify && !a.headless &&
+		now.Sub(a.recordNotifyAt) >= peakNotifyInterval
+	if notify {
+		a.recordNotifyAt = now
+	}
+	a.mutex.Unlock()
+
+	if save {
+		if err := a.saveRecords(); err != nil {
+			log.Printf("failed to save records: %v", err)
+		}
+	}
+	if notify {
+		a.app.SendNotification(fyne.NewNotification("APM Tracker", "New all-time best "+broken[0]+"!"))
+	}
+}
+
+func (a *APMTracker) formatRecords() string {
+	r := a.getRecords()
+	if r.BestAvg == 0 {
+		return fmt.Sprintf("All-time best: %d", r.PeakAPM)
+	}
+	return fmt.Sprintf("All-time best: %d   Best %s average: %.1f",
+		r.PeakAPM, formatSessionLength(recordAvgWindow), r.BestAvg)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"github.com/robotn/gohook"
+	"strings"
+	"time"
+)
+
+// KeyRegion groups keys pressed by one hand or finger. Key names are gohook's,
+// as in combos. Mouse input forms a region of its own, and keys outside every
+// region count as Other.
+type KeyRegion struct {
+	Name string   `json:"name"`
+	Keys []string `json:"keys"`
+}
+
+type regionPreset struct {
+	Name    string
+	Regions []KeyRegion
+}
+
+// Keycodes are physical positions, so the presets hold on any keyboard layout.
+var regionPresets = []regionPreset{
+	{"Hands", []KeyRegion{
+		{"Left hand", strings.Fields("esc ` 1 2 3 4 5 tab q w e r t a s d f g shift z x c v b ctrl alt space")},
+		{"Right hand", strings.Fields("6 7 8 9 0 - = y u i o p [ ] \\ h j k l ; ' n m , . / enter rshift ralt up down left right delete")},
+	}},
+	{"WASD fingers", []KeyRegion{
+		{"Pinky", strings.Fields("esc ` 1 tab q a shift z ctrl")},
+		{"Ring", strings.Fields("2 w s x")},
+		{"Middle", strings.Fields("3 e d c")},
+		{"Index", strings.Fields("4 5 r t f g v b")},
+		{"Thumb", strings.Fields("space alt")},
+		{"Right hand", strings.Fields("6 7 8 9 0 - = y u i o p [ ] \\ h j k l ; ' n m , . / enter rshift ralt up down left right delete")},
+	}},
+}
+
+type regionHit struct {
+	at     int64
+	region int // index into keyRegions, -1 for Other
+}
+
+type regionShare struct {
+	Name  string
+	Count int
+}
+
+// parseRegions reads one region per line as "name: keys", e.g.
+// "Left hand: q w e r". A key may only belong to one region.
+func parseRegions(text string) ([]KeyRegion, error) {
+	var regions []KeyRegion
+	seen := make(map[uint16]string)
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, keys, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("line %d: expected \"name: keys\"", i+1)
+		}
+		region := KeyRegion{Name: name}
+		for _, key := range strings.Fields(strings.ToLower(keys)) {
+			code, ok := hook.Keycode[key]
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown key %q", i+1, key)
+			}
+			if prev, dup := seen[code]; dup {
+				return nil, fmt.Errorf("line %d: %q is already in %s", i+1, key, prev)
+			}
+			seen[code] = name
+			region.Keys = append(region.Keys, key)
+		}
+		regions = append(regions, region)
+	}
+	return regions, nil
+}
+
+func formatRegions(regions []KeyRegion) string {
+	lines := make([]string, len(regions))
+	for i, r := range regions {
+		lines[i] = r.Name + ": " + strings.Join(r.Keys, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// regionIndex maps each keycode to its region's index.
+func regionIndex(regions []KeyRegion) map[uint16]int {
+	index := make(map[uint16]int)
+	for i, r := range regions {
+		for _, key := range r.Keys {
+			if code, ok := hook.Keycode[key]; ok {
+				index[code] = i
+			}
+		}
+	}
+	return index
+}
+
+func (a *APMTracker) getKeyRegions() (bool, []KeyRegion) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.regionsOn, a.keyRegions
+}
+
+// setKeyRegions starts the breakdown afresh when the regions change, since
+// earlier hits refer to the old regions by index.
+func (a *APMTracker) setKeyRegions(on bool, regions []KeyRegion) {
+	a.mutex.Lock()
+	changed := formatRegions(regions) != formatRegions(a.keyRegions)
+	a.regionsOn = on
+	a.keyRegions = regions
+	a.regionOf = regionIndex(regions)
+	a.mutex.Unlock()
+	if changed {
+		a.regionHits.Reset()
+	}
+	a.saveConfig()
+	a.showRegionLabel(on)
+	a.refreshLabels()
+}
+
+func (a *APMTracker) showRegionLabel(on bool) {
+	if a.regionLabel == nil {
+		return
+	}
+	if on {
+		a.regionLabel.Show()
+	} else {
+		a.regionLabel.Hide()
+	}
+}
+
+// regionBreakdown counts the window's actions per region, followed by Other
+// and Mouse.
+func (a *APMTracker) regionBreakdown(now time.Time, window time.Duration) []regionShare {
+	_, regions := a.getKeyRegions()
+	other := len(regions)
+	shares := make([]regionShare, other+2)
+	for i, r := range regions {
+		shares[i].Name = r.Name
+	}
+	shares[other].Name = "Other"
+	shares[other+1] = regionShare{"Mouse", countWithin(a.mouseActions, now, window)}
+
+	start := now.Add(-window).UnixNano()
+	a.regionHits.ForEachNewest(func(h regionHit) bool {
+		if h.at < start {
+			return false
+		}
+		if h.region < 0 || h.region >= other {
+			shares[other].Count++
+		} else {
+			shares[h.region].Count++
+		}
+		return true
+	})
+	if shares[other].Count == 0 {
+		shares = append(shares[:other], shares[other+1])
+	}
+	return shares
+}
+
+func formatBreakdown(shares []regionShare) string {
+	total := 0
+	for _, s := range shares {
+		total += s.Count
+	}
+	if total == 0 {
+		return "Balance: no input"
+	}
+	parts := make([]string, len(shares))
+	for i, s := range shares {
+		parts[i] = fmt.Sprintf("%s %.0f%%", s.Name, 100*float64(s.Count)/float64(total))
+	}
+	return "Balance: " + strings.Join(parts, "   ")
+}
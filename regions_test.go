@@ -0,0 +1,63 @@
+package main
+
+import (
+	"github.com/robotn/gohook"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegionPresetsParse(t *testing.T) {
+	for _, p := range regionPresets {
+		if _, err := parseRegions(formatRegions(p.Regions)); err != nil {
+			t.Errorf("preset %s: %v", p.Name, err)
+		}
+	}
+}
+
+func TestParseRegionsErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Left q w e", "expected"},
+		{"Left: q nosuchkey", "unknown key"},
+		{"Left: q w\nRight: w", "already in Left"},
+	}
+	for _, tt := range tests {
+		if _, err := parseRegions(tt.text); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseRegions(%q) = %v, want error containing %q", tt.text, err, tt.want)
+		}
+	}
+}
+
+func TestRegionBreakdown(t *testing.T) {
+	a, clock := newTestTracker(t)
+	a.keyRegions = regionPresets[0].Regions
+	a.regionOf = regionIndex(a.keyRegions)
+	click := hook.Event{Kind: hook.MouseDown, Button: 1}
+
+	// An old burst falls outside the window by the time of the check.
+	feedEvents(a, press("j"), release("j"), press("k"), release("k"))
+	clock.advance(2 * time.Minute)
+	feedEvents(a,
+		press("w"), release("w"), press("a"), release("a"), press("d"), release("d"),
+		press("j"), release("j"),
+		press("f5"), release("f5"),
+		click, click, click,
+	)
+
+	got := a.regionBreakdown(clock.now(), time.Minute)
+	want := []regionShare{{"Left hand", 3}, {"Right hand", 1}, {"Other", 1}, {"Mouse", 3}}
+	if len(got) != len(want) {
+		t.Fatalf("breakdown = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("breakdown[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if s := formatBreakdown(got); s != "Balance: Left hand 38%   Right hand 12%   Other 12%   Mouse 38%" {
+		t.Errorf("formatBreakdown = %q", s)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// The per-tick APM samples behind the percentiles, histogram, recent average
+// and recent peak are capped at a span of active time rather than a count, so
+// the cap means the same thing at every update interval. Once a session runs
+// longer, the oldest samples are dropped: the percentiles and histogram then
+// describe only the retained stretch, while the session average and peak,
+// which don't come from samples, still cover everything.
+const defaultSampleRetention = 4 * time.Hour
+
+var sampleRetentions = []time.Duration{
+	time.Hour,
+	4 * time.Hour,
+	12 * time.Hour,
+	24 * time.Hour,
+}
+
+const retentionHint = "Percentiles and the histogram only cover this much active time. Samples take 8 bytes per update."
+
+func formatRetention(d time.Duration) string {
+	return fmt.Sprintf("%d h", int(d.Hours()))
+}
+
+func (a *APMTracker) getSampleRetention() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.sampleRetain
+}
+
+func (a *APMTracker) setSampleRetention(d time.Duration) {
+	a.mutex.Lock()
+	a.sampleRetain = d
+	a.mutex.Unlock()
+	a.resizeSamples()
+	a.saveConfig()
+}
+
+// resizeSamples fits the sample buffer to the retention at the current update
+// interval, keeping the newest samples.
+func (a *APMTracker) resizeSamples() {
+	a.mutex.Lock()
+	capacity := max(int(a.sampleRetain/a.updateInterval), 1)
+	a.mutex.Unlock()
+	a.apmSamples.Resize(capacity)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"github.com/robotn/gohook"
+	"math"
+	"time"
+)
+
+const defaultSelfTestAPM = 120
+
+// syntheticSource scripts input for -selftest: a rate swinging around apm so
+// the graphs have something to show, mostly keys with every third action a
+// click. It exercises everything after capture without touching gohook.
+type syntheticSource struct {
+	apm  int
+	stop chan struct{}
+}
+
+func (s *syntheticSource) Start() <-chan Action {
+	out := make(chan Action, 256)
+	s.stop = make(chan struct{})
+	go s.run(out)
+	return out
+}
+
+func (s *syntheticSource) Stop() {
+	close(s.stop)
+}
+
+func (s *syntheticSource) run(out chan<- Action) {
+	defer close(out)
+	keys := []uint16{hook.Keycode["q"], hook.Keycode["w"], hook.Keycode["e"], hook.Keycode["r"]}
+	start := time.Now()
+	for i := 0; ; i++ {
+		// A 40 second cycle between half and one and a half times the rate.
+		phase := time.Since(start).Seconds() / 40 * 2 * math.Pi
+		rate := float64(s.apm) * (1 + 0.5*math.Sin(phase))
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(time.Duration(float64(time.Minute) / max(rate, 1))):
+		}
+		action := Action{Kind: KeyboardAction, Code: keys[i%len(keys)]}
+		if i%3 == 2 {
+			action = Action{Kind: MouseAction, Code: 1}
+		}
+		select {
+		case out <- action:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// startSelfTest feeds synthetic input through the same path as real capture.
+func (a *APMTracker) startSelfTest() {
+	src := &syntheticSource{apm: a.selfTest}
+	a.mutex.Lock()
+	a.synthetic = src
+	a.mutex.Unlock()
+	a.inputLog.status("self-test: synthesizing about %d APM", a.selfTest)
+	go a.consume(src)
+}
+
+// isSimulated reports input that isn't the user's own, which must not
+// overwrite their session, history or records.
+func (a *APMTracker) isSimulated() bool {
+	return a.replay != nil || a.selfTest > 0
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type Session struct {
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	PeakAPM      int       `json:"peak_apm"`
+	PeakAPMTime  time.Time `json:"peak_apm_time"`
+	MinAPM       *int      `json:"min_apm,omitempty"`
+	AverageAPM   float64   `json:"average_apm"`
+	TotalActions int       `json:"total_actions"`
+	Timestamps   []int64   `json:"timestamps"`
+}
+
+func sessionPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "session.json"), nil
+}
+
+func (a *APMTracker) snapshotSession() Session {
+	timestamps := a.actions.GetAll()
+	avgAPM := a.calculateAverageAPM()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	var minAPM *int
+	if a.minAPMSet {
+		m := a.minAPM
+		minAPM = &m
+	}
+	return Session{
+		StartTime:    a.startTime,
+		EndTime:      a.now(),
+		PeakAPM:      a.peakAPM,
+		PeakAPMTime:  a.peakAPMTime,
+		MinAPM:       minAPM,
+		AverageAPM:   avgAPM,
+		TotalActions: int(a.totalActions),
+		Timestamps:   timestamps,
+	}
+}
+
+func (a *APMTracker) saveSession(path string) error {
+	data, err := json.MarshalIndent(a.snapshotSession(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func loadSession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// resumeWindows are how soon after the last session a restart carries its
+// peak and min APM over; Off always starts fresh.
+var resumeWindows = []time.Duration{
+	0,
+	5 * time.Minute,
+	10 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+}
+
+const resumeHint = "Keep the last session's peak and min APM after a restart this soon"
+
+func (a *APMTracker) getResumeWindow() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.resumeWindow
+}
+
+func (a *APMTracker) setResumeWindow(d time.Duration) {
+	a.mutex.Lock()
+	a.resumeWindow = d
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+func (a *APMTracker) restoreSession(path string) {
+	resumeWindow := a.getResumeWindow()
+	if resumeWindow <= 0 {
+		return
+	}
+	session, err := loadSession(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("warning: no previous session at %s, starting fresh", path)
+		return
+	}
+	if err != nil {
+		log.Printf("warning: ignoring unreadable session %s: %v", path, err)
+		return
+	}
+	if a.now().Sub(session.EndTime) <= resumeWindow {
+		a.peakAPM = session.PeakAPM
+		a.peakAPMTime = session.PeakAPMTime
+		if session.MinAPM != nil {
+			a.minAPM, a.minAPMSet = *session.MinAPM, true
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResumeWindowPersists(t *testing.T) {
+	for _, d := range resumeWindows {
+		a, _ := newTestTracker(t)
+		a.setResumeWindow(d)
+		b := NewAPMTracker()
+		b.loadConfig()
+		if got := b.getResumeWindow(); got != d {
+			t.Errorf("resume window after reload = %s, want %s", got, d)
+		}
+	}
+}
+
+func TestResumeWindowDefault(t *testing.T) {
+	a, _ := newTestTracker(t)
+	a.loadConfig()
+	if got := a.getResumeWindow(); got != 10*time.Minute {
+		t.Errorf("resume window with no config = %s, want 10m", got)
+	}
+}
+
+func TestRestoreSession(t *testing.T) {
+	tests := []struct {
+		name   string
+		resume time.Duration
+		ago    time.Duration
+		want   int
+	}{
+		{"within the window", 10 * time.Minute, 5 * time.Minute, 250},
+		{"at the edge", 10 * time.Minute, 10 * time.Minute, 250},
+		{"too long ago", 10 * time.Minute, 10*time.Minute + time.Second, 0},
+		{"resume off", 0, time.Second, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev, clock := newTestTracker(t)
+			prev.peakAPM = 250
+			path := filepath.Join(t.TempDir(), "session.json")
+			if err := prev.saveSession(path); err != nil {
+				t.Fatal(err)
+			}
+
+			a := NewAPMTracker()
+			clock.advance(tt.ago)
+			a.now = clock.now
+			a.resumeWindow = tt.resume
+			a.restoreSession(path)
+			if a.peakAPM != tt.want {
+				t.Errorf("peak APM after restoring = %d, want %d", a.peakAPM, tt.want)
+			}
+		})
+	}
+}
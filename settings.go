@@ -0,0 +1,821 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"image/color"
+	"strconv"
+	"time"
+)
+
+var updateIntervals = []time.Duration{
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+}
+
+const defaultGraphInterval = 2 * time.Second
+
+var graphIntervals = []time.Duration{
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+}
+
+func formatFPS(d time.Duration) string {
+	return fmt.Sprintf("%g FPS", float64(time.Second)/float64(d))
+}
+
+var apmWindows = []time.Duration{
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+func formatWindow(d time.Duration) string {
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
+
+func (a *APMTracker) getAPMWindow() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.apmWindow
+}
+
+func (a *APMTracker) setAPMWindow(d time.Duration) {
+	a.mutex.Lock()
+	a.apmWindow = d
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+func (a *APMTracker) isSmoothAPS() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.smoothAPS
+}
+
+func (a *APMTracker) setSmoothAPS(on bool) {
+	a.mutex.Lock()
+	a.smoothAPS = on
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+func (a *APMTracker) getUpdateInterval() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.updateInterval
+}
+
+func (a *APMTracker) setUpdateInterval(d time.Duration) {
+	a.mutex.Lock()
+	a.updateInterval = d
+	a.mutex.Unlock()
+	a.resizeSamples()
+	a.saveConfig()
+}
+
+func (a *APMTracker) getGraphInterval() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.graphInterval
+}
+
+func (a *APMTracker) setGraphInterval(d time.Duration) {
+	a.mutex.Lock()
+	a.graphInterval = d
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+func formatGraphMax(apm int) string {
+	if apm == 0 {
+		return "Auto"
+	}
+	return fmt.Sprintf("%d APM", apm)
+}
+
+func validateInt(s string) error {
+	_, err := strconv.Atoi(s)
+	return err
+}
+
+func (a *APMTracker) showSettings() {
+	if a.settingsWindow != nil {
+		a.settingsWindow.RequestFocus()
+		return
+	}
+
+	intervalOptions := make([]string, len(updateIntervals))
+	for i, d := range updateIntervals {
+		intervalOptions[i] = d.String()
+	}
+	intervalSelect := widget.NewSelect(intervalOptions, func(s string) {
+		if d, err := time.ParseDuration(s); err == nil {
+			a.setUpdateInterval(d)
+		}
+	})
+	intervalSelect.Selected = a.getUpdateInterval().String()
+
+	graphFPSOptions := make([]string, len(graphIntervals))
+	for i, d := range graphIntervals {
+		graphFPSOptions[i] = formatFPS(d)
+	}
+	graphFPSSelect := widget.NewSelect(graphFPSOptions, func(s string) {
+		for _, d := range graphIntervals {
+			if formatFPS(d) == s {
+				a.setGraphInterval(d)
+			}
+		}
+	})
+	graphFPSSelect.Selected = formatFPS(a.getGraphInterval())
+
+	windowOptions := make([]string, len(apmWindows))
+	for i, d := range apmWindows {
+		windowOptions[i] = formatWindow(d)
+	}
+	windowSelect := widget.NewSelect(windowOptions, func(s string) {
+		if d, err := time.ParseDuration(s); err == nil {
+			a.setAPMWindow(d)
+		}
+	})
+	windowSelect.Selected = formatWindow(a.getAPMWindow())
+
+	decay, halfLife := a.getDecayMode()
+	apmModes := []string{"Window", "Decay"}
+	halfLifeOptions := make([]string, len(halfLives))
+	for i, d := range halfLives {
+		halfLifeOptions[i] = formatWindow(d)
+	}
+	halfLifeSelect := widget.NewSelect(halfLifeOptions, nil)
+	halfLifeSelect.Selected = formatWindow(halfLife)
+	apmModeRadio := widget.NewRadioGroup(apmModes, nil)
+	apmModeRadio.Horizontal = true
+	apmModeRadio.Selected = apmModes[0]
+	if decay {
+		apmModeRadio.Selected = apmModes[1]
+	}
+	apmModeRadio.OnChanged = func(s string) {
+		_, halfLife := a.getDecayMode()
+		a.setDecayMode(s == apmModes[1], halfLife)
+	}
+	halfLifeSelect.OnChanged = func(s string) {
+		if d, err := time.ParseDuration(s); err == nil {
+			a.setDecayMode(apmModeRadio.Selected == apmModes[1], d)
+		}
+	}
+
+	spamOptions := make([]string, len(spamThresholds))
+	for i, d := range spamThresholds {
+		spamOptions[i] = d.String()
+	}
+	spamSelect := widget.NewSelect(spamOptions, func(s string) {
+		if d, err := time.ParseDuration(s); err == nil {
+			a.setSpamThreshold(d)
+		}
+	})
+	spamSelect.Selected = a.getSpamThreshold().String()
+
+	smoothAPSCheck := widget.NewCheck(fmt.Sprintf("Average over %ds", apsSmoothingSeconds), a.setSmoothAPS)
+	smoothAPSCheck.Checked = a.isSmoothAPS()
+
+	emaSlider := widget.NewSlider(0.05, 1)
+	emaSlider.Step = 0.05
+	emaSlider.Value = a.getEMAAlpha()
+	emaSlider.OnChangeEnded = a.setEMAAlpha
+
+	recentOptions := make([]string, len(recentPeakWindows))
+	for i, d := range recentPeakWindows {
+		recentOptions[i] = formatSessionLength(d)
+	}
+	recentSelect := widget.NewSelect(recentOptions, func(s string) {
+		for _, d := range recentPeakWindows {
+			if formatSessionLength(d) == s {
+				a.setRecentPeakWindow(d)
+			}
+		}
+	})
+	recentSelect.Selected = formatSessionLength(a.getRecentPeakWindow())
+
+	recentAvgOptions := make([]string, len(recentAvgWindows))
+	for i, d := range recentAvgWindows {
+		recentAvgOptions[i] = formatSessionLength(d)
+	}
+	recentAvgSelect := widget.NewSelect(recentAvgOptions, func(s string) {
+		for _, d := range recentAvgWindows {
+			if formatSessionLength(d) == s {
+				a.setRecentAvgWindow(d)
+			}
+		}
+	})
+	recentAvgSelect.Selected = formatSessionLength(a.getRecentAvgWindow())
+
+	warmUpOptions := make([]string, len(warmUps))
+	for i, d := range warmUps {
+		warmUpOptions[i] = formatAgo(d)
+	}
+	warmUpSelect := widget.NewSelect(warmUpOptions, func(s string) {
+		for _, d := range warmUps {
+			if formatAgo(d) == s {
+				a.setWarmUp(d)
+			}
+		}
+	})
+	warmUpSelect.Selected = formatAgo(a.getWarmUp())
+
+	capacityOptions := make([]string, len(actionCapacities))
+	for i, n := range actionCapacities {
+		capacityOptions[i] = strconv.Itoa(n)
+	}
+	capacitySelect := widget.NewSelect(capacityOptions, func(s string) {
+		if n, err := strconv.Atoi(s); err == nil {
+			a.setActionCapacity(n)
+		}
+	})
+	capacitySelect.Selected = strconv.Itoa(a.getActionCapacity())
+
+	retentionOptions := make([]string, len(sampleRetentions))
+	for i, d := range sampleRetentions {
+		retentionOptions[i] = formatRetention(d)
+	}
+	retentionSelect := widget.NewSelect(retentionOptions, func(s string) {
+		for _, d := range sampleRetentions {
+			if formatRetention(d) == s {
+				a.setSampleRetention(d)
+			}
+		}
+	})
+	retentionSelect.Selected = formatRetention(a.getSampleRetention())
+	retentionItem := widget.NewFormItem("Samples kept", retentionSelect)
+	retentionItem.HintText = retentionHint
+
+	peakModes := []string{"Sampled", "Burst"}
+	peakModeRadio := widget.NewRadioGroup(peakModes, func(s string) {
+		a.setBurstPeak(s == peakModes[1])
+	})
+	peakModeRadio.Horizontal = true
+	if a.isBurstPeak() {
+		peakModeRadio.Selected = peakModes[1]
+	} else {
+		peakModeRadio.Selected = peakModes[0]
+	}
+	peakModeItem := widget.NewFormItem("Peak APM", peakModeRadio)
+	peakModeItem.HintText = peakHint
+
+	peakNotifyCheck := widget.NewCheck("Notify on a new peak APM", a.setPeakNotify)
+	peakNotifyCheck.Checked = a.isPeakNotify()
+	recordNotifyCheck := widget.NewCheck("Notify on a new all-time best", a.setRecordNotify)
+	recordNotifyCheck.Checked = a.isRecordNotify()
+
+	sessionOptions := make([]string, len(sessionLengths))
+	for i, d := range sessionLengths {
+		sessionOptions[i] = formatSessionLength(d)
+	}
+	sessionSelect := widget.NewSelect(sessionOptions, func(s string) {
+		for _, d := range sessionLengths {
+			if formatSessionLength(d) == s {
+				a.setSessionLength(d)
+			}
+		}
+	})
+	sessionSelect.Selected = formatSessionLength(a.getSessionLength())
+
+	resumeOptions := make([]string, len(resumeWindows))
+	for i, d := range resumeWindows {
+		resumeOptions[i] = formatSessionLength(d)
+	}
+	resumeSelect := widget.NewSelect(resumeOptions, func(s string) {
+		for _, d := range resumeWindows {
+			if formatSessionLength(d) == s {
+				a.setResumeWindow(d)
+			}
+		}
+	})
+	resumeSelect.Selected = formatSessionLength(a.getResumeWindow())
+	resumeItem := widget.NewFormItem("Resume within", resumeSelect)
+	resumeItem.HintText = resumeHint
+
+	idlePause, idleTimeout := a.getIdlePause()
+	idleOptions := make([]string, len(idleTimeouts))
+	for i, d := range idleTimeouts {
+		idleOptions[i] = formatAgo(d)
+	}
+	idleSelect := widget.NewSelect(idleOptions, nil)
+	idleSelect.Selected = formatAgo(idleTimeout)
+	idleCheck := widget.NewCheck("Pause while idle", nil)
+	idleCheck.Checked = idlePause
+	idleSelect.OnChanged = func(s string) {
+		for _, d := range idleTimeouts {
+			if formatAgo(d) == s {
+				a.setIdlePause(idleCheck.Checked, d)
+			}
+		}
+	}
+	idleCheck.OnChanged = func(on bool) {
+		_, timeout := a.getIdlePause()
+		a.setIdlePause(on, timeout)
+	}
+
+	targetEntry := widget.NewEntry()
+	targetEntry.SetPlaceHolder("0 to disable")
+	if target := a.getTargetAPM(); target > 0 {
+		targetEntry.SetText(strconv.Itoa(target))
+	}
+	targetEntry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		return validateInt(s)
+	}
+	targetEntry.OnSubmitted = func(s string) {
+		target, _ := strconv.Atoi(s)
+		a.setTargetAPM(target)
+	}
+
+	alertEnabled, alertThreshold, alertDuration := a.getAlertSettings()
+	alertCheck := widget.NewCheck("Beep when APM stays low", nil)
+	alertCheck.Checked = alertEnabled
+	alertThresholdEntry := widget.NewEntry()
+	alertThresholdEntry.SetText(strconv.Itoa(alertThreshold))
+	alertThresholdEntry.Validator = validateInt
+	alertSecondsEntry := widget.NewEntry()
+	alertSecondsEntry.SetText(strconv.Itoa(int(alertDuration.Seconds())))
+	alertSecondsEntry.Validator = validateInt
+	applyAlert := func() {
+		threshold, err := strconv.Atoi(alertThresholdEntry.Text)
+		if err != nil {
+			return
+		}
+		seconds, err := strconv.Atoi(alertSecondsEntry.Text)
+		if err != nil {
+			return
+		}
+		a.setAlertSettings(alertCheck.Checked, threshold, time.Duration(seconds)*time.Second)
+	}
+	alertCheck.OnChanged = func(bool) { applyAlert() }
+	alertThresholdEntry.OnSubmitted = func(string) { applyAlert() }
+	alertSecondsEntry.OnSubmitted = func(string) { applyAlert() }
+
+	graphModes := []string{"Bar", "Line"}
+	graphModeRadio := widget.NewRadioGroup(graphModes, func(s string) {
+		if s == "Line" {
+			a.setGraphMode(LineGraph)
+		} else {
+			a.setGraphMode(BarGraph)
+		}
+	})
+	graphModeRadio.Horizontal = true
+	graphModeRadio.Selected = graphModes[a.getGraphMode()]
+
+	histogramOptions := make([]string, len(histogramWidths))
+	for i, w := range histogramWidths {
+		histogramOptions[i] = strconv.Itoa(w)
+	}
+	histogramSelect := widget.NewSelect(histogramOptions, func(s string) {
+		if w, err := strconv.Atoi(s); err == nil {
+			a.setHistogramWidth(w)
+		}
+	})
+	histogramSelect.Selected = strconv.Itoa(a.getHistogramWidth())
+
+	themeRadio := widget.NewRadioGroup([]string{"Light", "Dark"}, func(s string) {
+		if s == "Dark" {
+			a.setTheme(darkTheme)
+		} else {
+			a.setTheme(lightTheme)
+		}
+	})
+	themeRadio.Horizontal = true
+	if a.getThemeName() == darkTheme {
+		themeRadio.Selected = "Dark"
+	} else {
+		themeRadio.Selected = "Light"
+	}
+
+	barColorButton := widget.NewButton("Choose…", func() {
+		picker := dialog.NewColorPicker("Bar color", "Pick a color for graph bars", func(c color.Color) {
+			a.setBarColor(c)
+		}, a.settingsWindow)
+		picker.Advanced = true
+		if c, _ := a.getBarStyle(); c != nil {
+			picker.SetColor(c)
+		}
+		picker.Show()
+	})
+	resetBarColor := widget.NewButton("Use theme", func() {
+		a.setBarColor(nil)
+	})
+
+	_, barWidth := a.getBarStyle()
+	barWidthSlider := widget.NewSlider(1, maxBarWidth)
+	barWidthSlider.Step = 1
+	barWidthSlider.Value = float64(barWidth)
+	barWidthSlider.OnChangeEnded = func(v float64) {
+		a.setBarWidth(int(v))
+	}
+
+	onTopCheck := widget.NewCheck("Keep mini view on top", a.setMiniOnTop)
+	onTopCheck.Checked = a.isMiniOnTop()
+
+	monitor, corner := a.getMiniPlacement()
+	monitorSelect := widget.NewSelect(a.monitorNames(), nil)
+	monitorSelect.PlaceHolder = "Primary"
+	monitorSelect.Selected = monitor
+	cornerSelect := widget.NewSelect(miniCorners, nil)
+	cornerSelect.Selected = corner
+	monitorSelect.OnChanged = func(s string) {
+		a.setMiniPlacement(s, cornerSelect.Selected)
+	}
+	cornerSelect.OnChanged = func(s string) {
+		a.setMiniPlacement(monitorSelect.Selected, s)
+	}
+
+	opacitySlider := widget.NewSlider(minMiniOpacity, maxMiniOpacity)
+	opacitySlider.Step = 0.05
+	opacitySlider.Value = a.getMiniOpacity()
+	opacitySlider.OnChangeEnded = a.setMiniOpacity
+
+	closeModes := []string{"Quit", "Keep tracking in the tray"}
+	closeRadio := widget.NewRadioGroup(closeModes, func(s string) {
+		a.setCloseToTray(s == closeModes[1])
+	})
+	if a.isCloseToTray() {
+		closeRadio.Selected = closeModes[1]
+	} else {
+		closeRadio.Selected = closeModes[0]
+	}
+
+	clickThroughCheck := widget.NewCheck("Let clicks pass through (leave with the hotkey)", a.setClickThrough)
+	clickThroughCheck.Checked = a.isClickThrough()
+	if !clickThroughSupported {
+		clickThroughCheck.Disable()
+	}
+
+	startMini, rememberView := a.getStartView()
+	var startMiniCheck, rememberViewCheck *widget.Check
+	startMiniCheck = widget.NewCheck("Start in mini view", func(on bool) {
+		a.setStartView(on, rememberViewCheck.Checked)
+	})
+	rememberViewCheck = widget.NewCheck("Start in the view last used", func(on bool) {
+		a.setStartView(startMiniCheck.Checked, on)
+	})
+	startMiniCheck.Checked = startMini
+	rememberViewCheck.Checked = rememberView
+
+	sparklineCheck := widget.NewCheck("Show the last minute as a sparkline", a.setSparkline)
+	sparklineCheck.Checked = a.isSparkline()
+
+	overlaySize, overlayBackground := a.getOverlayStyle()
+	overlaySizeSlider := widget.NewSlider(minOverlaySize, maxOverlaySize)
+	overlaySizeSlider.Step = 4
+	overlaySizeSlider.Value = float64(overlaySize)
+	overlayBgSlider := widget.NewSlider(0, 1)
+	overlayBgSlider.Step = 0.05
+	overlayBgSlider.Value = overlayBackground
+	overlaySizeSlider.OnChangeEnded = func(v float64) {
+		a.setOverlayStyle(float32(v), overlayBgSlider.Value)
+	}
+	overlayBgSlider.OnChangeEnded = func(v float64) {
+		a.setOverlayStyle(float32(overlaySizeSlider.Value), v)
+	}
+
+	excludeMods, ignoreRepeat := a.getKeyFilters()
+	var modsCheck, repeatCheck *widget.Check
+	modsCheck = widget.NewCheck("Ignore modifier keys", func(on bool) {
+		a.setKeyFilters(on, repeatCheck.Checked)
+	})
+	repeatCheck = widget.NewCheck("Ignore key auto-repeat", func(on bool) {
+		a.setKeyFilters(modsCheck.Checked, on)
+	})
+	modsCheck.Checked = excludeMods
+	repeatCheck.Checked = ignoreRepeat
+	gamepadCheck := widget.NewCheck("Count gamepad buttons", a.setCountGamepad)
+	gamepadCheck.Checked = a.isCountGamepad()
+	focusedCheck := widget.NewCheck("Ignore input in this app's windows", a.setIgnoreFocused)
+	focusedCheck.Checked = a.isIgnoreFocused()
+
+	autoSwitch, autoInterval := a.getAutoSwitch()
+	autoIntervalOptions := make([]string, len(autoSwitchIntervals))
+	for i, d := range autoSwitchIntervals {
+		autoIntervalOptions[i] = d.String()
+	}
+	autoIntervalSelect := widget.NewSelect(autoIntervalOptions, nil)
+	autoIntervalSelect.Selected = autoInterval.String()
+	autoSwitchCheck := widget.NewCheck("Switch profile with the focused game", nil)
+	autoSwitchCheck.Checked = autoSwitch
+	autoIntervalSelect.OnChanged = func(s string) {
+		if d, err := time.ParseDuration(s); err == nil {
+			a.setAutoSwitch(autoSwitchCheck.Checked, d)
+		}
+	}
+	autoSwitchCheck.OnChanged = func(on bool) {
+		_, interval := a.getAutoSwitch()
+		a.setAutoSwitch(on, interval)
+	}
+
+	profileExeEntry := widget.NewEntry()
+	profileExeEntry.SetPlaceHolder("e.g. sc2*.exe")
+	profileExeEntry.SetText(a.getProfileExe())
+	profileExeEntry.OnSubmitted = a.setProfileExe
+
+	hotkeyEntry := widget.NewEntry()
+	hotkeyEntry.SetText(a.hotkey.String())
+	hotkeyEntry.Validator = func(s string) error {
+		_, err := parseHotkey(s)
+		return err
+	}
+	hotkeyEntry.OnSubmitted = func(s string) {
+		if err := a.setHotkey(s); err != nil {
+			dialog.ShowError(err, a.settingsWindow)
+		}
+	}
+
+	graphMaxOptions := make([]string, len(graphMaxes))
+	for i, apm := range graphMaxes {
+		graphMaxOptions[i] = formatGraphMax(apm)
+	}
+	graphMaxSelect := widget.NewSelect(graphMaxOptions, func(s string) {
+		for _, apm := range graphMaxes {
+			if formatGraphMax(apm) == s {
+				a.setGraphMax(apm)
+			}
+		}
+	})
+	graphMaxSelect.Selected = formatGraphMax(a.getGraphMax())
+
+	copyFormats := []string{"Plain text", "Markdown table"}
+	copyRadio := widget.NewRadioGroup(copyFormats, func(s string) {
+		a.setCopyMarkdown(s == copyFormats[1])
+	})
+	copyRadio.Horizontal = true
+	if a.isCopyMarkdown() {
+		copyRadio.Selected = copyFormats[1]
+	} else {
+		copyRadio.Selected = copyFormats[0]
+	}
+
+	numbers := a.getNumberFormat()
+	decimalsSelect := widget.NewSelect([]string{"0", "1", "2", "3"}, nil)
+	decimalsSelect.Selected = strconv.Itoa(numbers.decimals)
+	sepOptions := make([]string, len(thousandsSeps))
+	for i, sep := range thousandsSeps {
+		sepOptions[i] = formatThousandsSep(sep)
+	}
+	sepSelect := widget.NewSelect(sepOptions, nil)
+	sepSelect.Selected = formatThousandsSep(numbers.sep)
+	decimalsSelect.OnChanged = func(s string) {
+		decimals, _ := strconv.Atoi(s)
+		a.setNumberFormat(decimals, a.getNumberFormat().sep)
+	}
+	sepSelect.OnChanged = func(s string) {
+		for _, sep := range thousandsSeps {
+			if formatThousandsSep(sep) == s {
+				a.setNumberFormat(a.getNumberFormat().decimals, sep)
+			}
+		}
+	}
+
+	layouts := []string{"Stacked", "Compact bar"}
+	layoutRadio := widget.NewRadioGroup(layouts, func(s string) {
+		a.setCompact(s == layouts[1])
+	})
+	layoutRadio.Horizontal = true
+	if a.isCompact() {
+		layoutRadio.Selected = layouts[1]
+	} else {
+		layoutRadio.Selected = layouts[0]
+	}
+
+	graphDots, currentLine := a.getGraphMarkers()
+	var dotsCheck, currentLineCheck *widget.Check
+	dotsCheck = widget.NewCheck("Mark each bucket's value", func(on bool) {
+		a.setGraphMarkers(on, currentLineCheck.Checked)
+	})
+	currentLineCheck = widget.NewCheck("Draw a line at the current APM", func(on bool) {
+		a.setGraphMarkers(dotsCheck.Checked, on)
+	})
+	dotsCheck.Checked = graphDots
+	currentLineCheck.Checked = currentLine
+
+	maOn, maWindow := a.getMovingAvg()
+	maOptions := make([]string, len(maWindows))
+	for i, d := range maWindows {
+		maOptions[i] = formatWindow(d)
+	}
+	maSelect := widget.NewSelect(maOptions, nil)
+	maSelect.Selected = formatWindow(maWindow)
+	maCheck := widget.NewCheck("Draw a moving average line", nil)
+	maCheck.Checked = maOn
+	maSelect.OnChanged = func(s string) {
+		if d, err := time.ParseDuration(s); err == nil {
+			a.setMovingAvg(maCheck.Checked, d)
+		}
+	}
+	maCheck.OnChanged = func(on bool) {
+		_, window := a.getMovingAvg()
+		a.setMovingAvg(on, window)
+	}
+
+	bucketModes := []string{"Sliding", "Wall clock"}
+	bucketRadio := widget.NewRadioGroup(bucketModes, func(s string) {
+		a.setWallClock(s == bucketModes[1])
+	})
+	bucketRadio.Horizontal = true
+	if a.isWallClock() {
+		bucketRadio.Selected = bucketModes[1]
+	} else {
+		bucketRadio.Selected = bucketModes[0]
+	}
+
+	stackedCheck := widget.NewCheck("Split bars into keyboard and mouse", a.setStackedGraph)
+	stackedCheck.Checked = a.isStackedGraph()
+
+	zonesOn, zones := a.getZones()
+	zonesCheck := widget.NewCheck("Tint the window by APM zone", nil)
+	zonesCheck.Checked = zonesOn
+	zonesEntry := widget.NewMultiLineEntry()
+	zonesEntry.SetPlaceHolder("150 #ff9900 Fast")
+	zonesEntry.SetText(formatZones(zones))
+	zonesEntry.Validator = func(s string) error {
+		_, err := parseZones(s)
+		return err
+	}
+	applyZones := func() {
+		zones, err := parseZones(zonesEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, a.settingsWindow)
+			return
+		}
+		a.setZones(zonesCheck.Checked, zones)
+	}
+	zonesCheck.OnChanged = func(bool) { applyZones() }
+	zonesApply := widget.NewButton("Apply zones", applyZones)
+
+	regionsOn, regions := a.getKeyRegions()
+	regionsCheck := widget.NewCheck("Show the key region balance", nil)
+	regionsCheck.Checked = regionsOn
+	regionsEntry := widget.NewMultiLineEntry()
+	regionsEntry.SetPlaceHolder("Left hand: q w e r")
+	regionsEntry.SetText(formatRegions(regions))
+	regionsEntry.Validator = func(s string) error {
+		_, err := parseRegions(s)
+		return err
+	}
+	presetOptions := make([]string, len(regionPresets))
+	for i, p := range regionPresets {
+		presetOptions[i] = p.Name
+	}
+	regionPresetSelect := widget.NewSelect(presetOptions, func(s string) {
+		for _, p := range regionPresets {
+			if p.Name == s {
+				regionsEntry.SetText(formatRegions(p.Regions))
+			}
+		}
+	})
+	regionPresetSelect.PlaceHolder = "Load a preset"
+	applyRegions := func() {
+		regions, err := parseRegions(regionsEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, a.settingsWindow)
+			return
+		}
+		a.setKeyRegions(regionsCheck.Checked, regions)
+	}
+	regionsCheck.OnChanged = func(bool) { applyRegions() }
+	regionsApply := widget.NewButton("Apply regions", applyRegions)
+
+	comboWeighting, combos := a.getCombos()
+	comboCheck := widget.NewCheck("Count combos extra towards weighted APM", nil)
+	comboCheck.Checked = comboWeighting
+	comboEntry := widget.NewMultiLineEntry()
+	comboEntry.SetPlaceHolder("q w e = 2")
+	comboEntry.SetText(formatCombos(combos))
+	comboEntry.Validator = func(s string) error {
+		_, err := parseCombos(s)
+		return err
+	}
+	applyCombos := func() {
+		combos, err := parseCombos(comboEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, a.settingsWindow)
+			return
+		}
+		a.setCombos(comboCheck.Checked, combos)
+	}
+	comboCheck.OnChanged = func(bool) { applyCombos() }
+	comboApply := widget.NewButton("Apply combos", applyCombos)
+
+	metricsForm := widget.NewForm(
+		widget.NewFormItem("Update interval", intervalSelect),
+		widget.NewFormItem("APM window", windowSelect),
+		widget.NewFormItem("APM mode", apmModeRadio),
+		widget.NewFormItem("Decay half-life", halfLifeSelect),
+		widget.NewFormItem("Spam under", spamSelect),
+		widget.NewFormItem("APS", smoothAPSCheck),
+		widget.NewFormItem("Smoothing alpha", emaSlider),
+		peakModeItem,
+		widget.NewFormItem("Recent peak over", recentSelect),
+		widget.NewFormItem("", peakNotifyCheck),
+		widget.NewFormItem("", recordNotifyCheck),
+		widget.NewFormItem("Recent average over", recentAvgSelect),
+		widget.NewFormItem("Min APM warm-up", warmUpSelect),
+		widget.NewFormItem("Session length", sessionSelect),
+		resumeItem,
+		widget.NewFormItem("Idle", idleCheck),
+		widget.NewFormItem("Idle after", idleSelect),
+		widget.NewFormItem("Actions kept", capacitySelect),
+		retentionItem,
+		widget.NewFormItem("Target APM", targetEntry),
+		widget.NewFormItem("Low APM alert", alertCheck),
+		widget.NewFormItem("Alert below APM", alertThresholdEntry),
+		widget.NewFormItem("Alert after (s)", alertSecondsEntry),
+	)
+	graphForm := widget.NewForm(
+		widget.NewFormItem("Graph refresh", graphFPSSelect),
+		widget.NewFormItem("Graph mode", graphModeRadio),
+		widget.NewFormItem("Graph buckets", bucketRadio),
+		widget.NewFormItem("", stackedCheck),
+		widget.NewFormItem("", dotsCheck),
+		widget.NewFormItem("", currentLineCheck),
+		widget.NewFormItem("", maCheck),
+		widget.NewFormItem("Average over", maSelect),
+		widget.NewFormItem("Graph Y axis", graphMaxSelect),
+		widget.NewFormItem("Bar color", container.NewHBox(barColorButton, resetBarColor)),
+		widget.NewFormItem("Bar width", barWidthSlider),
+		widget.NewFormItem("Histogram bucket (APM)", histogramSelect),
+	)
+	displayForm := widget.NewForm(
+		widget.NewFormItem("Theme", themeRadio),
+		widget.NewFormItem("Layout", layoutRadio),
+		widget.NewFormItem("Closing the window", closeRadio),
+		widget.NewFormItem("Copy stats as", copyRadio),
+		widget.NewFormItem("Average decimals", decimalsSelect),
+		widget.NewFormItem("Thousands separator", sepSelect),
+		widget.NewFormItem("Zones", zonesCheck),
+		widget.NewFormItem("Zone bands", zonesEntry),
+		widget.NewFormItem("", zonesApply),
+		widget.NewFormItem("Key regions", regionsCheck),
+		widget.NewFormItem("Region keys", regionsEntry),
+		widget.NewFormItem("Presets", regionPresetSelect),
+		widget.NewFormItem("", regionsApply),
+	)
+	miniForm := widget.NewForm(
+		widget.NewFormItem("Mini view", onTopCheck),
+		widget.NewFormItem("Mini view monitor", monitorSelect),
+		widget.NewFormItem("Mini view corner", cornerSelect),
+		widget.NewFormItem("Mini view opacity", opacitySlider),
+		widget.NewFormItem("", sparklineCheck),
+		widget.NewFormItem("", clickThroughCheck),
+		widget.NewFormItem("", startMiniCheck),
+		widget.NewFormItem("", rememberViewCheck),
+		widget.NewFormItem("Overlay font size", overlaySizeSlider),
+		widget.NewFormItem("Overlay background", overlayBgSlider),
+	)
+	inputForm := widget.NewForm(
+		widget.NewFormItem("Toggle hotkey", hotkeyEntry),
+		widget.NewFormItem("Keys", modsCheck),
+		widget.NewFormItem("", repeatCheck),
+		widget.NewFormItem("", focusedCheck),
+		widget.NewFormItem("", gamepadCheck),
+		widget.NewFormItem("Combos", comboCheck),
+		widget.NewFormItem("Combo weights", comboEntry),
+		widget.NewFormItem("", comboApply),
+		widget.NewFormItem("Profiles", autoSwitchCheck),
+		widget.NewFormItem("Check focus every", autoIntervalSelect),
+		widget.NewFormItem("Profile executable", profileExeEntry),
+	)
+	for _, item := range a.eventChecks() {
+		inputForm.AppendItem(item)
+	}
+	for _, item := range a.cooldownSelects() {
+		inputForm.AppendItem(item)
+	}
+
+	// Each tab scrolls on its own so nothing ends up off-screen.
+	tab := func(name string, form *widget.Form) *container.TabItem {
+		return container.NewTabItem(name, container.NewVScroll(container.NewPadded(form)))
+	}
+	tabs := container.NewAppTabs(
+		tab("Metrics", metricsForm),
+		tab("Graph", graphForm),
+		tab("Display", displayForm),
+		tab("Mini view", miniForm),
+		tab("Input", inputForm),
+	)
+
+	a.settingsWindow = a.app.NewWindow("Settings")
+	a.settingsWindow.SetContent(tabs)
+	a.settingsWindow.Resize(fyne.NewSize(420, 560))
+	a.settingsWindow.SetOnClosed(func() {
+		a.settingsWindow = nil
+	})
+	a.settingsWindow.Show()
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"fyne.io/fyne/v2/test"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+// Opening settings only shows the current values; nothing is saved until a
+// control is changed.
+func TestShowSettingsSavesNothing(t *testing.T) {
+	a, _ := newTestTracker(t)
+	a.app = test.NewApp()
+	a.window = a.app.NewWindow("")
+	a.showSettings()
+	defer a.settingsWindow.Close()
+
+	path, err := configPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("opening settings wrote the config (stat: %v)", err)
+	}
+}
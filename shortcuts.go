@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"github.com/robotn/gohook"
+	"slices"
+)
+
+// In-window shortcuts use the platform's shortcut modifier: Ctrl, or Cmd on
+// macOS. The global hook sees these keystrokes too, so gohookSource drops them
+// while one of our windows has focus, even when ignoreFocused is off and other
+// input in our windows is counted.
+var shortcutKeys = map[uint16]bool{
+	hook.Keycode["f"]: true,
+	hook.Keycode["p"]: true,
+	hook.Keycode["r"]: true,
+}
+
+var shortcutModifiers = []uint16{
+	29,   // ctrl
+	3613, // right ctrl
+	3675, // cmd
+	3676, // right cmd
+}
+
+func isShortcutModifier(code uint16) bool {
+	return slices.Contains(shortcutModifiers, code)
+}
+
+func (a *APMTracker) setupShortcuts() {
+	add := func(key fyne.KeyName, action func()) {
+		shortcut := &desktop.CustomShortcut{KeyName: key, Modifier: fyne.KeyModifierShortcutDefault}
+		a.window.Canvas().AddShortcut(shortcut, func(fyne.Shortcut) {
+			action()
+		})
+	}
+	add(fyne.KeyF, a.toggleFreeze)
+	add(fyne.KeyP, a.togglePause)
+	add(fyne.KeyR, a.reset)
+
+	lifecycle := a.app.Lifecycle()
+	lifecycle.SetOnEnteredForeground(func() {
+		a.setFocused(true)
+		a.recheckAccessibility()
+	})
+	lifecycle.SetOnExitedForeground(func() {
+		a.setFocused(false)
+	})
+}
+
+func (a *APMTracker) setFocused(focused bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.focused = focused
+}
+
+func (a *APMTracker) isIgnoreFocused() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.ignoreFocused
+}
+
+func (a *APMTracker) setIgnoreFocused(ignore bool) {
+	a.mutex.Lock()
+	a.ignoreFocused = ignore
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+func (a *APMTracker) isFocused() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.focused
+}
+
+// isShortcut reports whether a press completes one of our in-window
+// shortcuts. Like heldKeys, it is only used from the input loop.
+func (a *APMTracker) isShortcut(code uint16) bool {
+	if !shortcutKeys[code] || !a.isFocused() {
+		return false
+	}
+	for _, mod := range shortcutModifiers {
+		if a.heldKeys[mod] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"github.com/robotn/gohook"
+	"testing"
+)
+
+func TestShortcutsNotCounted(t *testing.T) {
+	rctrl := uint16(3613)
+	tests := []struct {
+		name    string
+		hotkey  string
+		focused bool
+		events  []hook.Event
+		want    int64
+	}{
+		{"in our window", defaultHotkey, true,
+			[]hook.Event{press("ctrl"), press("p"), release("p"), release("ctrl")}, 0},
+		{"in another window", defaultHotkey, false,
+			[]hook.Event{press("ctrl"), press("p"), release("p"), release("ctrl")}, 2},
+		{"rebound hotkey", "alt+shift+h", true,
+			[]hook.Event{press("ctrl"), press("r"), release("r"), release("ctrl")}, 0},
+		{"right ctrl", defaultHotkey, true, []hook.Event{
+			{Kind: hook.KeyHold, Keycode: rctrl}, press("f"), release("f"), {Kind: hook.KeyUp, Keycode: rctrl}}, 0},
+		{"cmd", defaultHotkey, true,
+			[]hook.Event{press("cmd"), press("p"), release("p"), release("cmd")}, 0},
+		{"right cmd", defaultHotkey, true,
+			[]hook.Event{press("rcmd"), press("p"), release("p"), release("rcmd")}, 0},
+		{"another ctrl chord", defaultHotkey, true,
+			[]hook.Event{press("ctrl"), press("x"), release("x"), release("ctrl")}, 2},
+		{"ctrl alone", defaultHotkey, true,
+			[]hook.Event{press("ctrl"), release("ctrl")}, 1},
+		{"ctrl-click", "alt+shift+h", true,
+			[]hook.Event{press("ctrl"), {Kind: hook.MouseDown, Button: 1}, release("ctrl")}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := newTestTracker(t)
+			if err := a.hotkey.Set(tt.hotkey); err != nil {
+				t.Fatal(err)
+			}
+			// Count modifiers and our own window, so only the shortcut
+			// check can drop the presses.
+			a.excludeMods = false
+			a.ignoreFocused = false
+			a.focused = tt.focused
+			feedEvents(a, tt.events...)
+			if got := a.getTotalActions(); got != tt.want {
+				t.Errorf("total actions = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import "log"
+
+// shutdown releases everything the tracker holds. Closing the window, Quit in
+// the menus and SIGINT/SIGTERM all end up here, possibly more than once, so
+// the work is guarded to run exactly once: gohook panics if ended twice.
+func (a *APMTracker) shutdown() {
+	a.cleanup.Do(func() {
+		a.stopUpdates()
+		a.stopMetricsServer()
+		a.stopWebSocketServer()
+		a.stopDashboard()
+		if err := a.StopRecording(); err != nil {
+			log.Printf("failed to finish recording: %v", err)
+		}
+
+		a.mutex.Lock()
+		src := a.hookSource
+		if a.synthetic != nil {
+			a.synthetic.Stop()
+		}
+		if a.gamepad != nil {
+			a.gamepad.Stop()
+		}
+		a.mutex.Unlock()
+		if src != nil {
+			src.Stop()
+		}
+
+		// A replayed or synthetic session is not the user's own, so keep the
+		// saved one.
+		if a.isSimulated() {
+			return
+		}
+		a.mutex.Lock()
+		dirty := a.recordsDirty
+		a.mutex.Unlock()
+		if dirty {
+			if err := a.saveRecords(); err != nil {
+				log.Printf("failed to save records: %v", err)
+			}
+		}
+		if entry := a.historyEntry(); entry.Total > 0 {
+			if path, err := historyPath(); err != nil {
+				log.Printf("failed to locate history file: %v", err)
+			} else if err := appendHistory(path, entry); err != nil {
+				log.Printf("failed to update history: %v", err)
+			}
+		}
+		if path, err := sessionPath(); err != nil {
+			log.Printf("failed to locate session file: %v", err)
+		} else if err := a.saveSession(path); err != nil {
+			log.Printf("failed to save session: %v", err)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Closing the window, Quit and a signal may all race to shut down; the hook
+// must still be ended exactly once.
+func TestShutdownEndsHookOnce(t *testing.T) {
+	a, _ := newTestTracker(t)
+	a.hookSource = &gohookSource{tracker: a}
+	a.addAction(KeyboardAction, 30)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.shutdown()
+		}()
+	}
+	wg.Wait()
+	a.shutdown()
+
+	if n := strings.Count(a.inputLog.text(), "stopping hook"); n != 1 {
+		t.Errorf("hook stopped %d times, want once", n)
+	}
+	path, err := sessionPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("session not saved: %v", err)
+	}
+}
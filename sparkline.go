@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"image"
+	"time"
+)
+
+const (
+	sparklineWidth   = 80
+	sparklineHeight  = 20
+	sparklineSpan    = time.Minute
+	sparklineBuckets = 30
+)
+
+var (
+	miniSize      = fyne.NewSize(120, 30)
+	miniSparkSize = fyne.NewSize(120+sparklineWidth+8, 30)
+)
+
+func (a *APMTracker) isSparkline() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.sparkline
+}
+
+func (a *APMTracker) setSparkline(on bool) {
+	a.mutex.Lock()
+	a.sparkline = on
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.layoutMiniView()
+}
+
+func (a *APMTracker) layoutMiniView() {
+	if a.isSparkline() {
+		a.miniSpark.Show()
+		a.miniWindow.Resize(miniSparkSize)
+	} else {
+		a.miniSpark.Hide()
+		a.miniWindow.Resize(miniSize)
+	}
+}
+
+// updateSparkline draws the last minute of activity as a bare line on a
+// transparent background, cheap enough to redraw every tick.
+func (a *APMTracker) updateSparkline() {
+	buckets := bucketActions(a.actions, a.now(), sparklineSpan, sparklineBuckets)
+	peak := 1
+	for _, count := range buckets {
+		peak = max(peak, count)
+	}
+	c, _ := a.getBarStyle()
+	if c == nil {
+		c = a.getPalette().Bar
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, sparklineWidth, sparklineHeight))
+	prevX, prevY := 0, 0
+	for i, count := range buckets {
+		// Newest bucket first, drawn on the right as in the timeline.
+		x := sparklineWidth - 1 - i*(sparklineWidth-1)/(len(buckets)-1)
+		y := sparklineHeight - 1 - count*(sparklineHeight-2)/peak
+		if i > 0 {
+			drawLine(img, prevX, prevY, x, y, c)
+		}
+		prevX, prevY = x, y
+	}
+	a.miniSpark.Image = img
+	a.miniSpark.Refresh()
+}
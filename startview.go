@@ -0,0 +1,28 @@
+package main
+
+func (a *APMTracker) getStartView() (mini, remember bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.startMini, a.rememberView
+}
+
+func (a *APMTracker) setStartView(mini, remember bool) {
+	a.mutex.Lock()
+	a.startMini = mini
+	a.rememberView = remember
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+// rememberCurrentView makes the view just switched to the next start's view,
+// if the user asked for that.
+func (a *APMTracker) rememberCurrentView() {
+	a.mutex.Lock()
+	if !a.rememberView || a.startMini == a.miniView {
+		a.mutex.Unlock()
+		return
+	}
+	a.startMini = a.miniView
+	a.mutex.Unlock()
+	a.saveConfig()
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2/dialog"
+	"sort"
+	"time"
+)
+
+const minReliableSamples = 20
+
+// Stats is a point-in-time snapshot of the tracker's metrics, independent of
+// any widgets so that it can drive the GUI and headless output alike.
+type Stats struct {
+	Window       time.Duration
+	HalfLife     time.Duration // zero for the hard window
+	Current      int
+	Smoothed     float64
+	Weighted     int
+	Effective    int
+	Spam         float64 // share of the window's actions the spam filter drops
+	Keyboard     int
+	Mouse        int
+	APS          int
+	SmoothedAPS  float64
+	SmoothAPS    bool
+	Peak         int
+	PeakTime     time.Time
+	RecentPeak   int
+	Min          int // -1 until warmed up
+	Average      float64
+	RecentAvg    float64
+	TotalActions int64
+	LastHour     int
+	Elapsed      time.Duration
+	Paused       bool
+	Idle         bool
+}
+
+// updateStats computes the current metrics and folds them into the session
+// peak, sampled or burst as chosen.
+func (a *APMTracker) updateStats() Stats {
+	window := a.getAPMWindow()
+	counts := a.countActions(a.now(), window)
+	current := perMinute(counts.window, window)
+	decay, halfLife := a.getDecayMode()
+	if decay {
+		current = a.calculateDecayAPM(halfLife)
+	} else {
+		halfLife = 0
+	}
+	stats := Stats{
+		Window:       window,
+		HalfLife:     halfLife,
+		Current:      current,
+		Smoothed:     a.smoothedAPM(),
+		Weighted:     a.calculateWeightedAPM(),
+		Effective:    perMinute(counts.effective, window),
+		Spam:         counts.spamIndex(),
+		Keyboard:     a.calculateKeyboardAPM(),
+		Mouse:        a.calculateMouseAPM(),
+		APS:          counts.second,
+		SmoothedAPS:  float64(counts.smoothing) / apsSmoothingSeconds,
+		SmoothAPS:    a.isSmoothAPS(),
+		RecentPeak:   a.calculateRecentPeak(),
+		Min:          a.getMinAPM(),
+		Average:      a.calculateAverageAPM(),
+		RecentAvg:    a.calculateRecentAverage(),
+		TotalActions: a.getTotalActions(),
+		LastHour:     counts.hour,
+		Elapsed:      a.activeElapsed(),
+		Paused:       a.isPaused(),
+		Idle:         a.isIdle(),
+	}
+
+	burstPeak, burstAt := a.updateBurstPeak(a.now(), window)
+	a.mutex.Lock()
+	if stats.Current > a.peakAPM {
+		a.peakAPM = stats.Current
+		a.peakAPMTime = a.now()
+	}
+	stats.Peak, stats.PeakTime = a.peakAPM, a.peakAPMTime
+	if a.burstMode {
+		stats.Peak, stats.PeakTime = burstPeak, burstAt
+	}
+	a.lastStats = stats
+	a.mutex.Unlock()
+
+	return stats
+}
+
+// actionCounts are the trailing-window counts behind several stats. The
+// smoothing window damps the noise of a single one-second APS sample, and
+// effective counts the APM window with spam filtered out.
+type actionCounts struct {
+	second    int
+	smoothing int
+	window    int
+	effective int
+	hour      int
+}
+
+// countActions gathers every count in one newest-first pass that stops at the
+// oldest window, instead of one scan over the buffer per metric.
+func (a *APMTracker) countActions(now time.Time, window time.Duration) actionCounts {
+	nanos := now.UnixNano()
+	secondStart := nanos - int64(time.Second)
+	smoothingStart := nanos - int64(apsSmoothingSeconds*time.Second)
+	windowStart := nanos - int64(window)
+	hourStart := nanos - int64(time.Hour)
+	oldest := min(windowStart, hourStart)
+
+	a.scratchMutex.Lock()
+	defer a.scratchMutex.Unlock()
+	var c actionCounts
+	inWindow := a.scratch[:0]
+	a.actions.ForEachNewest(func(t int64) bool {
+		if t < oldest {
+			return false
+		}
+		if t >= secondStart {
+			c.second++
+		}
+		if t >= smoothingStart {
+			c.smoothing++
+		}
+		if t >= windowStart {
+			c.window++
+			inWindow = append(inWindow, t)
+		}
+		if t >= hourStart {
+			c.hour++
+		}
+		return true
+	})
+	a.scratch = inWindow
+
+	// Walk the window oldest first so each counted action starts a new spam
+	// interval.
+	threshold := a.getSpamThreshold().Nanoseconds()
+	var lastCounted int64
+	for i := len(inWindow) - 1; i >= 0; i-- {
+		if t := inWindow[i]; i == len(inWindow)-1 || t-lastCounted >= threshold {
+			c.effective++
+			lastCounted = t
+		}
+	}
+	return c
+}
+
+var spamThresholds = []time.Duration{
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	150 * time.Millisecond,
+}
+
+// getSpamThreshold is the gap under which a repeat action is spam, shared by
+// effective APM and the spam index.
+func (a *APMTracker) getSpamThreshold() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.spamThreshold
+}
+
+func (a *APMTracker) setSpamThreshold(d time.Duration) {
+	a.mutex.Lock()
+	a.spamThreshold = d
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+// spamIndex is the share of actions in the window that followed the previous
+// counted one within the spam threshold, i.e. raw against effective APM.
+func (c actionCounts) spamIndex() float64 {
+	if c.window == 0 {
+		return 0
+	}
+	return float64(c.window-c.effective) / float64(c.window)
+}
+
+func perMinute(count int, window time.Duration) int {
+	return int(float64(count) * float64(time.Minute) / float64(window))
+}
+
+func (a *APMTracker) getTotalActions() int64 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.totalActions
+}
+
+// latestStats returns the snapshot taken on the most recent tick, so readers
+// outside the update loop see exactly what the GUI shows.
+func (a *APMTracker) latestStats() Stats {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.lastStats
+}
+
+// percentile returns the nearest-rank percentile p (0-100) of sorted.
+func percentile(sorted []int, p float64) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func (a *APMTracker) sampleAPM() {
+	if a.isPaused() || a.isIdle() {
+		return
+	}
+	current := a.calculateCurrentAPM()
+	a.apmSamples.Append(current)
+	a.updateEMA(current)
+}
+
+func (a *APMTracker) calculatePercentiles() (median, p95, n int) {
+	samples := a.apmSamples.GetAll()
+	sort.Ints(samples)
+	return percentile(samples, 50), percentile(samples, 95), len(samples)
+}
+
+func (a *APMTracker) showStats() {
+	median, p95, n := a.calculatePercentiles()
+	var msg string
+	switch {
+	case n == 0:
+		msg = "No APM samples recorded yet."
+	case n < minReliableSamples:
+		msg = fmt.Sprintf("Median APM: %d\n95th percentile APM: %d\n\nOnly %d samples so far; these will settle as the session continues.", median, p95, n)
+	default:
+		msg = fmt.Sprintf("Median APM: %d\n95th percentile APM: %d\n\nBased on %d samples.", median, p95, n)
+	}
+	dialog.ShowInformation("Session Stats", msg, a.window)
+}
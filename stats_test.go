@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// countActionsByScan is countActions done the straightforward way, one scan
+// of the buffer per metric.
+func countActionsByScan(a *APMTracker, now time.Time, window time.Duration) actionCounts {
+	c := actionCounts{
+		second:    countWithin(a.actions, now, time.Second),
+		smoothing: countWithin(a.actions, now, apsSmoothingSeconds*time.Second),
+		window:    countWithin(a.actions, now, window),
+		hour:      countWithin(a.actions, now, time.Hour),
+	}
+	windowStart := now.Add(-window).UnixNano()
+	threshold := a.getSpamThreshold().Nanoseconds()
+	counted := false
+	var lastCounted int64
+	for _, t := range a.actions.GetAll() {
+		if t >= windowStart && (!counted || t-lastCounted >= threshold) {
+			c.effective++
+			lastCounted, counted = t, true
+		}
+	}
+	return c
+}
+
+// fillActions records n actions spaced gap apart, ending at the clock's time.
+func fillActions(a *APMTracker, clock *fakeClock, n int, gap time.Duration) {
+	clock.advance(-time.Duration(n) * gap)
+	for i := 0; i < n; i++ {
+		clock.advance(gap)
+		a.addAction(KeyboardAction, 0)
+	}
+}
+
+func TestCountActions(t *testing.T) {
+	a, clock := newTestTracker(t)
+	fillActions(a, clock, 3000, 1500*time.Millisecond)
+	// A burst of spam on top.
+	for i := 0; i < 20; i++ {
+		clock.advance(10 * time.Millisecond)
+		a.addAction(MouseAction, 1)
+	}
+	for _, window := range apmWindows {
+		if got, want := a.countActions(clock.now(), window), countActionsByScan(a, clock.now(), window); got != want {
+			t.Errorf("window %s: countActions = %+v, want %+v", window, got, want)
+		}
+	}
+}
+
+// burst returns n action times gap apart, the first at start.
+func burst(start time.Duration, n int, gap time.Duration) []time.Duration {
+	times := make([]time.Duration, n)
+	for i := range times {
+		times[i] = start + time.Duration(i)*gap
+	}
+	return times
+}
+
+func TestEffectiveAPM(t *testing.T) {
+	// Action times are offsets into a minute-long window that ends at 60s.
+	var bursts, straddle []time.Duration
+	for i := 0; i < 5; i++ {
+		bursts = append(bursts, burst(time.Duration(i)*10*time.Second, 4, 10*time.Millisecond)...)
+	}
+	straddle = append(burst(-30*time.Millisecond, 6, 10*time.Millisecond), burst(30*time.Second, 1, 0)...)
+	tests := []struct {
+		name              string
+		times             []time.Duration
+		window, effective int
+	}{
+		{"empty", nil, 0, 0},
+		{"steady", burst(time.Second, 60, time.Second), 60, 60},
+		{"bursts", bursts, 20, 5},
+		{"burst across the window start", straddle, 4, 2},
+		{"burst as long as the threshold", burst(0, 6, 10*time.Millisecond), 6, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, clock := newTestTracker(t)
+			a.spamThreshold = 50 * time.Millisecond
+			start := clock.now()
+			for _, at := range tt.times {
+				clock.t = start.Add(at)
+				a.addAction(KeyboardAction, 30)
+			}
+			c := a.countActions(start.Add(time.Minute), time.Minute)
+			if c.window != tt.window || c.effective != tt.effective {
+				t.Errorf("window %d, effective %d; want %d and %d", c.window, c.effective, tt.window, tt.effective)
+			}
+		})
+	}
+}
+
+func BenchmarkCountActions(b *testing.B) {
+	a, clock := newTestTracker(b)
+	fillActions(a, clock, defaultActionCapacity, 100*time.Millisecond)
+	b.Run("single pass", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			a.countActions(clock.now(), time.Minute)
+		}
+	})
+	b.Run("scan per metric", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			countActionsByScan(a, clock.now(), time.Minute)
+		}
+	})
+}
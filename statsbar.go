@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+)
+
+// mainParts are the pieces of the main window, kept so the layout can be
+// switched without rebuilding the bound labels.
+type mainParts struct {
+	header   []fyne.CanvasObject
+	stats    []fyne.CanvasObject
+	rangeRow fyne.CanvasObject
+	buttons  []fyne.CanvasObject
+}
+
+func (a *APMTracker) isCompact() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.compact
+}
+
+func (a *APMTracker) setCompact(on bool) {
+	a.mutex.Lock()
+	a.compact = on
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.layoutMain()
+}
+
+// layoutMain either stacks everything as before or puts the stats in one
+// scrolling bar so the graph can take the rest of the window. Either way the
+// zone tint sits behind it all.
+func (a *APMTracker) layoutMain() {
+	p := a.mainParts
+	if !a.isCompact() {
+		var objects []fyne.CanvasObject
+		objects = append(objects, p.header...)
+		objects = append(objects, p.stats...)
+		objects = append(objects, p.rangeRow, a.graphTabs)
+		objects = append(objects, p.buttons...)
+		a.window.SetContent(container.NewStack(a.zoneBg, container.NewVBox(objects...)))
+		return
+	}
+	top := container.NewVBox(p.header...)
+	top.Add(container.NewHScroll(container.NewHBox(p.stats...)))
+	top.Add(p.rangeRow)
+	bottom := container.NewGridWithColumns(4, p.buttons...)
+	a.window.SetContent(container.NewStack(a.zoneBg, container.NewBorder(top, bottom, nil, nil, a.graphTabs)))
+}
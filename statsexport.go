@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// StatsExport is the document written by Export JSON. Its field names are a
+// file format other tools parse: add fields, never rename them.
+type StatsExport struct {
+	ExportedAt      time.Time   `json:"exported_at"`
+	SessionStart    time.Time   `json:"session_start"`
+	DurationSeconds float64     `json:"duration_seconds"`
+	CurrentAPM      int         `json:"current_apm"`
+	PeakAPM         int         `json:"peak_apm"`
+	MinAPM          *int        `json:"min_apm"`
+	AverageAPM      float64     `json:"average_apm"`
+	KeyboardAPM     int         `json:"keyboard_apm"`
+	MouseAPM        int         `json:"mouse_apm"`
+	TotalActions    int64       `json:"total_actions"`
+	Keys            []InputStat `json:"keys"`
+	MouseButtons    []InputStat `json:"mouse_buttons"`
+}
+
+type InputStat struct {
+	Name  string `json:"name"`
+	Code  uint16 `json:"code"`
+	Count int    `json:"count"`
+}
+
+func inputStats(kc *KeyCounter, name func(uint16) string) []InputStat {
+	counts := kc.Top(maxTrackedKeys)
+	// Always an array, never null, so empty sessions parse the same way.
+	result := make([]InputStat, 0, len(counts))
+	for _, c := range counts {
+		result = append(result, InputStat{Name: name(c.Code), Code: c.Code, Count: c.Count})
+	}
+	return result
+}
+
+func (a *APMTracker) snapshotStats() StatsExport {
+	stats := a.latestStats()
+	export := StatsExport{
+		ExportedAt:      time.Now(),
+		DurationSeconds: a.activeElapsed().Seconds(),
+		CurrentAPM:      stats.Current,
+		PeakAPM:         stats.Peak,
+		AverageAPM:      stats.Average,
+		KeyboardAPM:     stats.Keyboard,
+		MouseAPM:        stats.Mouse,
+		TotalActions:    a.getTotalActions(),
+		Keys:            inputStats(a.keyStats, keyName),
+		MouseButtons:    inputStats(a.mouseStats, mouseButtonName),
+	}
+	if m := a.getMinAPM(); m >= 0 {
+		export.MinAPM = &m
+	}
+	a.mutex.Lock()
+	export.SessionStart = a.startTime
+	a.mutex.Unlock()
+	return export
+}
+
+func (a *APMTracker) exportStatsJSON(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := a.writeStatsJSON(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (a *APMTracker) writeStatsJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(a.snapshotStats())
+}
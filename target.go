@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fyne.io/fyne/v2/widget"
+	"image"
+	"image/color"
+)
+
+func (a *APMTracker) getTargetAPM() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.targetAPM
+}
+
+func (a *APMTracker) setTargetAPM(target int) {
+	a.mutex.Lock()
+	a.targetAPM = max(target, 0)
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.refresh()
+}
+
+// targetImportance colors the APM readout green at or above the target and red
+// below it; with no target set the label keeps its normal style.
+func (a *APMTracker) targetImportance(currentAPM int) widget.Importance {
+	target := a.getTargetAPM()
+	switch {
+	case target <= 0:
+		return widget.MediumImportance
+	case currentAPM >= target:
+		return widget.SuccessImportance
+	default:
+		return widget.DangerImportance
+	}
+}
+
+func drawDashedLine(img *image.RGBA, y int, c color.Color) {
+	for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+		if x%10 < 6 {
+			setPixel(img, x, y, c)
+			setPixel(img, x, y+1, c)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"image/color"
+)
+
+const (
+	lightTheme = "light"
+	darkTheme  = "dark"
+)
+
+type Palette struct {
+	Background color.Color
+	Bar        color.Color
+	Mouse      color.Color
+	Grid       color.Color
+	Axis       color.Color
+	Target     color.Color
+	Compare    color.Color
+	Average    color.Color
+}
+
+var (
+	lightPalette = Palette{
+		Background: color.White,
+		Bar:        color.RGBA{0, 0, 255, 255},
+		Mouse:      color.RGBA{255, 150, 0, 255},
+		Grid:       color.RGBA{220, 220, 220, 255},
+		Axis:       color.RGBA{80, 80, 80, 255},
+		Target:     color.RGBA{220, 40, 40, 255},
+		Compare:    color.RGBA{180, 180, 200, 255},
+		Average:    color.RGBA{0, 160, 80, 255},
+	}
+	darkPalette = Palette{
+		Background: color.RGBA{30, 30, 34, 255},
+		Bar:        color.RGBA{90, 160, 255, 255},
+		Mouse:      color.RGBA{255, 180, 70, 255},
+		Grid:       color.RGBA{60, 60, 66, 255},
+		Axis:       color.RGBA{190, 190, 190, 255},
+		Target:     color.RGBA{255, 110, 90, 255},
+		Compare:    color.RGBA{85, 85, 100, 255},
+		Average:    color.RGBA{90, 220, 130, 255},
+	}
+)
+
+// variantTheme pins the default theme to one variant regardless of the
+// desktop preference.
+type variantTheme struct {
+	fyne.Theme
+	variant fyne.ThemeVariant
+}
+
+func (t variantTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	return t.Theme.Color(name, t.variant)
+}
+
+func (a *APMTracker) getPalette() Palette {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.palette
+}
+
+const (
+	defaultBarWidth = 5
+	maxBarWidth     = 12
+)
+
+// getBarStyle returns the user's bar color, or nil to follow the palette.
+func (a *APMTracker) getBarStyle() (color.Color, int) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.barColor, a.barWidth
+}
+
+func (a *APMTracker) setBarColor(c color.Color) {
+	a.mutex.Lock()
+	a.barColor = c
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.updateGraph()
+}
+
+func (a *APMTracker) setBarWidth(w int) {
+	a.mutex.Lock()
+	a.barWidth = min(max(w, 1), maxBarWidth)
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.updateGraph()
+}
+
+func formatHexColor(c color.Color) string {
+	if c == nil {
+		return ""
+	}
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+func parseHexColor(s string) (color.Color, error) {
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return nil, fmt.Errorf("invalid color %q: %w", s, err)
+	}
+	return color.RGBA{r, g, b, 255}, nil
+}
+
+func (a *APMTracker) getThemeName() string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.themeName
+}
+
+func (a *APMTracker) setTheme(name string) {
+	a.mutex.Lock()
+	a.themeName = name
+	if name == darkTheme {
+		a.palette = darkPalette
+	} else {
+		a.palette = lightPalette
+	}
+	a.mutex.Unlock()
+
+	a.applyTheme()
+	a.saveConfig()
+	a.styleOverlay()
+	a.refresh()
+}
+
+func (a *APMTracker) applyTheme() {
+	variant := theme.VariantLight
+	if a.getThemeName() == darkTheme {
+		variant = theme.VariantDark
+	}
+	a.app.Settings().SetTheme(variantTheme{theme.DefaultTheme(), variant})
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2/dialog"
+	"time"
+)
+
+var sessionLengths = []time.Duration{
+	0,
+	5 * time.Minute,
+	10 * time.Minute,
+	15 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+}
+
+func formatClock(d time.Duration) string {
+	d = max(d, 0).Round(time.Second)
+	h := d / time.Hour
+	m := d % time.Hour / time.Minute
+	s := d % time.Minute / time.Second
+	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+}
+
+func formatSessionLength(d time.Duration) string {
+	if d == 0 {
+		return "Off"
+	}
+	return fmt.Sprintf("%d min", int(d.Minutes()))
+}
+
+func (a *APMTracker) getSessionLength() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.sessionLength
+}
+
+func (a *APMTracker) setSessionLength(d time.Duration) {
+	a.mutex.Lock()
+	a.sessionLength = d
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+func (a *APMTracker) sessionClock() string {
+	elapsed := formatClock(a.activeElapsed())
+	if length := a.getSessionLength(); length > 0 {
+		return fmt.Sprintf("Session: %s / %s", elapsed, formatClock(length))
+	}
+	return "Session: " + elapsed
+}
+
+// checkSessionEnd pauses counting once a fixed-length session has run its
+// course and shows a summary. It fires once per session; resuming afterwards
+// carries on as an open-ended session until the next reset.
+func (a *APMTracker) checkSessionEnd(stats Stats) {
+	elapsed := a.activeElapsed()
+	a.mutex.Lock()
+	if a.sessionDone || a.sessionLength == 0 || a.paused || elapsed < a.sessionLength {
+		a.mutex.Unlock()
+		return
+	}
+	a.sessionDone = true
+	a.mutex.Unlock()
+
+	a.togglePause()
+	msg := fmt.Sprintf("Duration: %s\nPeak APM: %d\nAverage APM: %.2f\nTotal actions: %d",
+		formatClock(elapsed), stats.Peak, stats.Average, stats.TotalActions)
+	dialog.ShowInformation("Session Complete", msg, a.window)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+func (a *APMTracker) setupTray() {
+	desk, ok := a.app.(desktop.App)
+	if !ok {
+		return
+	}
+
+	a.trayAPMItem = fyne.NewMenuItem("APM: 0", nil)
+	a.trayAPMItem.Disabled = true
+	a.trayPauseItem = fyne.NewMenuItem("Pause", func() {
+		a.togglePause()
+	})
+	a.trayMenu = fyne.NewMenu("APM Tracker",
+		a.trayAPMItem,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Show Main Window", func() {
+			if a.isMiniView() {
+				a.toggleView()
+			} else {
+				a.window.Show()
+			}
+		}),
+		fyne.NewMenuItem("Toggle Mini View", func() {
+			a.toggleView()
+		}),
+		a.trayPauseItem,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Quit", func() {
+			a.onClosing()
+		}),
+	)
+	desk.SetSystemTrayMenu(a.trayMenu)
+}
+
+func (a *APMTracker) updateTray(currentAPM int) {
+	if a.trayMenu == nil {
+		return
+	}
+	a.trayAPMItem.Label = fmt.Sprintf("APM: %d", currentAPM)
+	if a.isPaused() {
+		a.trayPauseItem.Label = "Resume"
+	} else {
+		a.trayPauseItem.Label = "Pause"
+	}
+	a.trayMenu.Refresh()
+}
+
+func (a *APMTracker) isCloseToTray() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.closeToTray
+}
+
+func (a *APMTracker) setCloseToTray(on bool) {
+	a.mutex.Lock()
+	a.closeToTray = on
+	a.mutex.Unlock()
+	a.saveConfig()
+}
+
+// closeMainWindow quits, or with close-to-tray just hides the window and
+// leaves input capture and updates running; Quit in the tray then ends it.
+// Without a tray there would be no way back, so it always quits.
+func (a *APMTracker) closeMainWindow() {
+	if a.isCloseToTray() && a.trayMenu != nil {
+		a.window.Hide()
+		return
+	}
+	a.onClosing()
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"time"
+)
+
+const (
+	trendWidth  = 600
+	trendHeight = 220
+	// trendMargin leaves room for the date labels under the plot.
+	trendMargin = 18
+
+	allProfiles = "All profiles"
+)
+
+// TrendRenderer plots peak and average APM per saved session against the
+// session date, oldest on the left.
+type TrendRenderer struct {
+	Palette Palette
+}
+
+func (r TrendRenderer) Render(entries []HistoryEntry) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, trendWidth, trendHeight))
+	clearImage(img, r.Palette.Background)
+	if len(entries) == 0 {
+		drawText(img, trendWidth/2-70, trendHeight/2, "No sessions recorded", r.Palette.Axis)
+		return img
+	}
+
+	first, last := entries[0].Date, entries[len(entries)-1].Date
+	top := 1.0
+	for _, e := range entries {
+		top = max(top, float64(e.Peak))
+	}
+	plotHeight := trendHeight - trendMargin
+	x := func(t time.Time) int {
+		// A lone session, or several at the same moment, sit mid-chart.
+		if !last.After(first) {
+			return trendWidth / 2
+		}
+		return 8 + int(float64(t.Sub(first))/float64(last.Sub(first))*(trendWidth-17))
+	}
+	y := func(v float64) int {
+		return plotHeight - 1 - int(v/top*float64(plotHeight-20))
+	}
+	plot := func(value func(HistoryEntry) float64, c color.Color) {
+		fill := image.NewUniform(c)
+		prevX, prevY := 0, 0
+		for i, e := range entries {
+			px, py := x(e.Date), y(value(e))
+			if i > 0 {
+				drawLine(img, prevX, prevY, px, py, c)
+			}
+			draw.Draw(img, image.Rect(px-1, py-1, px+2, py+2).Intersect(img.Rect), fill, image.Point{}, draw.Src)
+			prevX, prevY = px, py
+		}
+	}
+
+	draw.Draw(img, image.Rect(0, plotHeight, trendWidth, plotHeight+1), image.NewUniform(r.Palette.Grid), image.Point{}, draw.Src)
+	plot(func(e HistoryEntry) float64 { return float64(e.Peak) }, r.Palette.Bar)
+	plot(func(e HistoryEntry) float64 { return e.Average }, r.Palette.Average)
+
+	drawText(img, 4, 13, fmt.Sprintf("%.0f APM", top), r.Palette.Axis)
+	drawText(img, trendWidth-110, 13, "peak", r.Palette.Bar)
+	drawText(img, trendWidth-70, 13, "average", r.Palette.Average)
+	drawText(img, 4, trendHeight-4, first.Format("Jan 2"), r.Palette.Axis)
+	if last.After(first) {
+		label := last.Format("Jan 2")
+		drawText(img, trendWidth-4-len(label)*7, trendHeight-4, label, r.Palette.Axis)
+	}
+	return img
+}
@@ -0,0 +1,18 @@
+package main
+
+import "fmt"
+
+// Build information, set at link time:
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%d)"
+var (
+	version   = "dev"
+	commit    = "dev"
+	buildDate = "dev"
+)
+
+const repoURL = "https://github.com/erfianugrah/apmgo"
+
+func versionString() string {
+	return fmt.Sprintf("apmgo %s (commit %s, built %s)", version, commit, buildDate)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/net/websocket"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWebSocketPort = 9100
+	clientSendBuffer     = 8
+)
+
+type overlayMessage struct {
+	Current int     `json:"current"`
+	Peak    int     `json:"peak"`
+	Avg     float64 `json:"avg"`
+	APS     int     `json:"aps"`
+	TS      int64   `json:"ts"`
+}
+
+type wsClient struct {
+	send chan []byte
+}
+
+// Hub fans each tick's stats out to every connected overlay. Broadcasting
+// never blocks: a client whose buffer is full is assumed gone and dropped.
+type Hub struct {
+	mutex   sync.Mutex
+	clients map[*wsClient]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{clients: make(map[*wsClient]struct{})}
+}
+
+func (h *Hub) add() *wsClient {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	c := &wsClient{send: make(chan []byte, clientSendBuffer)}
+	h.clients[c] = struct{}{}
+	return c
+}
+
+func (h *Hub) remove(c *wsClient) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.clients[c]; ok {
+		delete(h.clients, c)
+		close(c.send)
+	}
+}
+
+func (h *Hub) Broadcast(stats Stats) {
+	msg, err := json.Marshal(overlayMessage{
+		Current: stats.Current,
+		Peak:    stats.Peak,
+		Avg:     stats.Average,
+		APS:     stats.APS,
+		TS:      time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for c := range h.clients {
+		select {
+		case c.send <- msg:
+		default:
+			delete(h.clients, c)
+			close(c.send)
+		}
+	}
+}
+
+func (h *Hub) Close() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for c := range h.clients {
+		delete(h.clients, c)
+		close(c.send)
+	}
+}
+
+func (h *Hub) serve(conn *websocket.Conn) {
+	c := h.add()
+	defer h.remove(c)
+
+	// Overlays never send anything; reading only serves to notice when the
+	// peer goes away.
+	go func() {
+		io.Copy(io.Discard, conn)
+		h.remove(c)
+	}()
+
+	for msg := range c.send {
+		if _, err := conn.Write(msg); err != nil {
+			return
+		}
+	}
+}
+
+func (a *APMTracker) startWebSocketServer(port int) {
+	a.hub = NewHub()
+	mux := http.NewServeMux()
+	mux.Handle("/", websocket.Server{Handler: a.hub.serve})
+	a.wsServer = &http.Server{Addr: fmt.Sprintf("localhost:%d", port), Handler: mux}
+
+	go func() {
+		err := a.wsServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("websocket server: %v", err)
+		}
+	}()
+}
+
+func (a *APMTracker) stopWebSocketServer() {
+	if a.wsServer == nil {
+		return
+	}
+	a.hub.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := a.wsServer.Shutdown(ctx); err != nil {
+		log.Printf("websocket server shutdown: %v", err)
+	}
+}
+
+func (a *APMTracker) broadcast(stats Stats) {
+	if a.hub != nil {
+		a.hub.Broadcast(stats)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import "fyne.io/fyne/v2"
+
+// Fyne has no portable always-on-top, positioning or transparency support, so
+// these reach through to the native window handle and defer to the
+// per-platform helpers.
+
+func setAlwaysOnTop(w fyne.Window, onTop bool) {
+	runNative(w, func(context any) {
+		setNativeAlwaysOnTop(context, onTop)
+	})
+}
+
+const (
+	minMiniOpacity = 0.2
+	maxMiniOpacity = 1.0
+)
+
+func setOpacity(w fyne.Window, opacity float64) {
+	runNative(w, func(context any) {
+		setNativeOpacity(context, min(max(opacity, minMiniOpacity), maxMiniOpacity))
+	})
+}
+
+func (a *APMTracker) getMiniOpacity() float64 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.miniOpacity
+}
+
+func (a *APMTracker) setMiniOpacity(opacity float64) {
+	a.mutex.Lock()
+	a.miniOpacity = min(max(opacity, minMiniOpacity), maxMiniOpacity)
+	a.mutex.Unlock()
+	a.saveConfig()
+	if a.isMiniView() {
+		setOpacity(a.miniWindow, opacity)
+	}
+}
+
+func setClickThrough(w fyne.Window, on bool) {
+	runNative(w, func(context any) {
+		setNativeClickThrough(context, on)
+	})
+}
+
+func (a *APMTracker) isMiniView() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.miniView
+}
+
+func (a *APMTracker) isMiniOnTop() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.miniOnTop
+}
+
+func (a *APMTracker) setMiniOnTop(on bool) {
+	a.mutex.Lock()
+	a.miniOnTop = on
+	a.mutex.Unlock()
+	a.saveConfig()
+	if a.isMiniView() {
+		setAlwaysOnTop(a.miniWindow, on)
+	}
+}
+
+func (a *APMTracker) isClickThrough() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.clickThrough
+}
+
+// setClickThrough makes the mini view and stream overlay ignore the mouse so
+// clicks reach the game beneath. Dragging them stops working, and only the
+// hotkey or tray can leave the mini view.
+func (a *APMTracker) setClickThrough(on bool) {
+	a.mutex.Lock()
+	a.clickThrough = on
+	a.mutex.Unlock()
+	a.saveConfig()
+	if a.isMiniView() {
+		setClickThrough(a.miniWindow, on)
+	}
+	if a.overlayWindow != nil {
+		setClickThrough(a.overlayWindow, on)
+	}
+}
@@ -0,0 +1,93 @@
+//go:build darwin
+
+package main
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Cocoa
+#import <Cocoa/Cocoa.h>
+
+static void apm_set_floating(uintptr_t w, int floating) {
+	NSWindow *win = (NSWindow *)w;
+	[win setLevel:floating ? NSFloatingWindowLevel : NSNormalWindowLevel];
+}
+
+// GLFW reports monitors with a top-left origin, while AppKit places windows
+// relative to the bottom of the primary screen.
+static void apm_move(uintptr_t w, int x, int y) {
+	NSWindow *win = (NSWindow *)w;
+	CGFloat top = NSMaxY([[[NSScreen screens] objectAtIndex:0] frame]);
+	[win setFrameTopLeftPoint:NSMakePoint(x, top - y)];
+}
+
+static void apm_set_alpha(uintptr_t w, double alpha) {
+	[(NSWindow *)w setAlphaValue:alpha];
+}
+
+static void apm_set_ignores_mouse(uintptr_t w, int ignore) {
+	[(NSWindow *)w setIgnoresMouseEvents:ignore ? YES : NO];
+}
+
+static void apm_position(uintptr_t w, int *x, int *y) {
+	NSWindow *win = (NSWindow *)w;
+	CGFloat top = NSMaxY([[[NSScreen screens] objectAtIndex:0] frame]);
+	NSRect frame = [win frame];
+	*x = (int)frame.origin.x;
+	*y = (int)(top - NSMaxY(frame));
+}
+*/
+import "C"
+
+import "fyne.io/fyne/v2/driver"
+
+func setNativeAlwaysOnTop(context any, onTop bool) {
+	ctx, ok := context.(driver.MacWindowContext)
+	if !ok || ctx.NSWindow == 0 {
+		return
+	}
+	floating := C.int(0)
+	if onTop {
+		floating = 1
+	}
+	C.apm_set_floating(C.uintptr_t(ctx.NSWindow), floating)
+}
+
+func setNativePosition(context any, x, y int) {
+	ctx, ok := context.(driver.MacWindowContext)
+	if !ok || ctx.NSWindow == 0 {
+		return
+	}
+	C.apm_move(C.uintptr_t(ctx.NSWindow), C.int(x), C.int(y))
+}
+
+func getNativePosition(context any) (x, y int, ok bool) {
+	ctx, ok := context.(driver.MacWindowContext)
+	if !ok || ctx.NSWindow == 0 {
+		return 0, 0, false
+	}
+	var cx, cy C.int
+	C.apm_position(C.uintptr_t(ctx.NSWindow), &cx, &cy)
+	return int(cx), int(cy), true
+}
+
+func setNativeOpacity(context any, opacity float64) {
+	ctx, ok := context.(driver.MacWindowContext)
+	if !ok || ctx.NSWindow == 0 {
+		return
+	}
+	C.apm_set_alpha(C.uintptr_t(ctx.NSWindow), C.double(opacity))
+}
+
+const clickThroughSupported = true
+
+func setNativeClickThrough(context any, on bool) {
+	ctx, ok := context.(driver.MacWindowContext)
+	if !ok || ctx.NSWindow == 0 {
+		return
+	}
+	ignore := C.int(0)
+	if on {
+		ignore = 1
+	}
+	C.apm_set_ignores_mouse(C.uintptr_t(ctx.NSWindow), ignore)
+}
@@ -0,0 +1,17 @@
+//go:build !windows && !darwin && !((linux || freebsd || openbsd || netbsd) && !wayland)
+
+package main
+
+func setNativeAlwaysOnTop(context any, onTop bool) {}
+
+func setNativePosition(context any, x, y int) {}
+
+func getNativePosition(context any) (x, y int, ok bool) {
+	return 0, 0, false
+}
+
+func setNativeOpacity(context any, opacity float64) {}
+
+const clickThroughSupported = false
+
+func setNativeClickThrough(context any, on bool) {}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"testing"
+)
+
+func TestMiniOnTopPersists(t *testing.T) {
+	a, _ := newTestTracker(t)
+	a.loadConfig()
+	if !a.isMiniOnTop() {
+		t.Error("mini view not on top by default")
+	}
+	for _, on := range []bool{false, true} {
+		a.setMiniOnTop(on)
+		b := NewAPMTracker()
+		b.loadConfig()
+		if got := b.isMiniOnTop(); got != on {
+			t.Errorf("on top after reload = %v, want %v", got, on)
+		}
+	}
+}
+
+// The hotkey toggles the view from the input goroutine while update ticks
+// read it.
+func TestToggleViewDuringUpdates(t *testing.T) {
+	a, _ := newTestTracker(t)
+	a.app = test.NewApp()
+	a.window = a.app.NewWindow("")
+	a.miniWindow = a.app.NewWindow("")
+	a.currentLabel = widget.NewLabel("")
+	a.miniLabel = newDragLabel("")
+	a.rememberView = true
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			a.toggleView()
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		a.refreshLabels()
+	}
+	<-done
+	if a.isMiniView() {
+		t.Error("in the mini view after an even number of toggles")
+	}
+	if mini, _ := a.getStartView(); mini {
+		t.Error("remembered the mini view after ending in the main view")
+	}
+}
@@ -0,0 +1,95 @@
+//go:build windows
+
+package main
+
+import (
+	"fyne.io/fyne/v2/driver"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32            = syscall.NewLazyDLL("user32.dll")
+	procSetWindowPos  = user32.NewProc("SetWindowPos")
+	procGetWindowRect = user32.NewProc("GetWindowRect")
+	procGetWindowLong = user32.NewProc("GetWindowLongW")
+	procSetWindowLong = user32.NewProc("SetWindowLongW")
+	procSetLayered    = user32.NewProc("SetLayeredWindowAttributes")
+)
+
+const (
+	hwndTopmost   = ^uintptr(0) // HWND_TOPMOST (-1)
+	hwndNoTopmost = ^uintptr(1) // HWND_NOTOPMOST (-2)
+	swpNoSize     = 0x0001
+	swpNoMove     = 0x0002
+	swpNoZOrder   = 0x0004
+	swpNoActivate = 0x0010
+
+	gwlExStyle      = ^uintptr(19) // GWL_EXSTYLE (-20)
+	wsExLayered     = 0x00080000
+	wsExTransparent = 0x00000020
+	lwaAlpha        = 0x00000002
+)
+
+func setNativeAlwaysOnTop(context any, onTop bool) {
+	ctx, ok := context.(driver.WindowsWindowContext)
+	if !ok || ctx.HWND == 0 {
+		return
+	}
+	after := hwndNoTopmost
+	if onTop {
+		after = hwndTopmost
+	}
+	procSetWindowPos.Call(ctx.HWND, after, 0, 0, 0, 0, swpNoSize|swpNoMove|swpNoActivate)
+}
+
+func setNativePosition(context any, x, y int) {
+	ctx, ok := context.(driver.WindowsWindowContext)
+	if !ok || ctx.HWND == 0 {
+		return
+	}
+	procSetWindowPos.Call(ctx.HWND, 0, uintptr(x), uintptr(y), 0, 0, swpNoSize|swpNoZOrder|swpNoActivate)
+}
+
+func getNativePosition(context any) (x, y int, ok bool) {
+	ctx, ok := context.(driver.WindowsWindowContext)
+	if !ok || ctx.HWND == 0 {
+		return 0, 0, false
+	}
+	var rect struct{ Left, Top, Right, Bottom int32 }
+	if r, _, _ := procGetWindowRect.Call(ctx.HWND, uintptr(unsafe.Pointer(&rect))); r == 0 {
+		return 0, 0, false
+	}
+	return int(rect.Left), int(rect.Top), true
+}
+
+func setNativeOpacity(context any, opacity float64) {
+	ctx, ok := context.(driver.WindowsWindowContext)
+	if !ok || ctx.HWND == 0 {
+		return
+	}
+	style, _, _ := procGetWindowLong.Call(ctx.HWND, gwlExStyle)
+	procSetWindowLong.Call(ctx.HWND, gwlExStyle, style|wsExLayered)
+	procSetLayered.Call(ctx.HWND, 0, uintptr(opacity*255), lwaAlpha)
+}
+
+const clickThroughSupported = true
+
+// setNativeClickThrough uses WS_EX_TRANSPARENT, which only passes clicks on
+// for a layered window. A window made layered here needs its alpha set, or
+// it would stop drawing.
+func setNativeClickThrough(context any, on bool) {
+	ctx, ok := context.(driver.WindowsWindowContext)
+	if !ok || ctx.HWND == 0 {
+		return
+	}
+	style, _, _ := procGetWindowLong.Call(ctx.HWND, gwlExStyle)
+	if !on {
+		procSetWindowLong.Call(ctx.HWND, gwlExStyle, style&^wsExTransparent)
+		return
+	}
+	procSetWindowLong.Call(ctx.HWND, gwlExStyle, style|wsExLayered|wsExTransparent)
+	if style&wsExLayered == 0 {
+		procSetLayered.Call(ctx.HWND, 0, 255, lwaAlpha)
+	}
+}
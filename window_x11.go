@@ -0,0 +1,141 @@
+//go:build (linux || freebsd || openbsd || netbsd) && !wayland
+
+package main
+
+/*
+#cgo LDFLAGS: -lX11 -lXext
+#include <X11/Xlib.h>
+#include <X11/Xatom.h>
+#include <X11/extensions/shape.h>
+#include <stdlib.h>
+
+static void apm_set_state(unsigned long win, int enable, const char *state) {
+	Display *d = XOpenDisplay(NULL);
+	if (!d) {
+		return;
+	}
+	XEvent ev = {0};
+	ev.xclient.type = ClientMessage;
+	ev.xclient.window = win;
+	ev.xclient.message_type = XInternAtom(d, "_NET_WM_STATE", False);
+	ev.xclient.format = 32;
+	ev.xclient.data.l[0] = enable ? 1 : 0;
+	ev.xclient.data.l[1] = XInternAtom(d, state, False);
+	ev.xclient.data.l[3] = 1;
+	XSendEvent(d, DefaultRootWindow(d), False,
+		SubstructureRedirectMask | SubstructureNotifyMask, &ev);
+	XFlush(d);
+	XCloseDisplay(d);
+}
+
+static void apm_move(unsigned long win, int x, int y) {
+	Display *d = XOpenDisplay(NULL);
+	if (!d) {
+		return;
+	}
+	XMoveWindow(d, win, x, y);
+	XFlush(d);
+	XCloseDisplay(d);
+}
+
+static void apm_set_opacity(unsigned long win, unsigned long opacity) {
+	Display *d = XOpenDisplay(NULL);
+	if (!d) {
+		return;
+	}
+	Atom prop = XInternAtom(d, "_NET_WM_WINDOW_OPACITY", False);
+	XChangeProperty(d, win, prop, XA_CARDINAL, 32, PropModeReplace,
+		(unsigned char *)&opacity, 1);
+	XFlush(d);
+	XCloseDisplay(d);
+}
+
+// An empty input shape lets clicks fall through to the window below;
+// resetting it to None restores the default of the whole window.
+static void apm_set_click_through(unsigned long win, int enable) {
+	Display *d = XOpenDisplay(NULL);
+	if (!d) {
+		return;
+	}
+	if (enable) {
+		XShapeCombineRectangles(d, win, ShapeInput, 0, 0, NULL, 0, ShapeSet, Unsorted);
+	} else {
+		XShapeCombineMask(d, win, ShapeInput, 0, 0, None, ShapeSet);
+	}
+	XFlush(d);
+	XCloseDisplay(d);
+}
+
+static int apm_position(unsigned long win, int *x, int *y) {
+	Display *d = XOpenDisplay(NULL);
+	if (!d) {
+		return 0;
+	}
+	Window child;
+	int ok = XTranslateCoordinates(d, win, DefaultRootWindow(d), 0, 0, x, y, &child);
+	XCloseDisplay(d);
+	return ok;
+}
+*/
+import "C"
+
+import (
+	"fyne.io/fyne/v2/driver"
+	"unsafe"
+)
+
+func setNativeAlwaysOnTop(context any, onTop bool) {
+	ctx, ok := context.(driver.X11WindowContext)
+	if !ok || ctx.WindowHandle == 0 {
+		return
+	}
+	state := C.CString("_NET_WM_STATE_ABOVE")
+	defer C.free(unsafe.Pointer(state))
+	enable := C.int(0)
+	if onTop {
+		enable = 1
+	}
+	C.apm_set_state(C.ulong(ctx.WindowHandle), enable, state)
+}
+
+func setNativePosition(context any, x, y int) {
+	ctx, ok := context.(driver.X11WindowContext)
+	if !ok || ctx.WindowHandle == 0 {
+		return
+	}
+	C.apm_move(C.ulong(ctx.WindowHandle), C.int(x), C.int(y))
+}
+
+func getNativePosition(context any) (x, y int, ok bool) {
+	ctx, ok := context.(driver.X11WindowContext)
+	if !ok || ctx.WindowHandle == 0 {
+		return 0, 0, false
+	}
+	var cx, cy C.int
+	if C.apm_position(C.ulong(ctx.WindowHandle), &cx, &cy) == 0 {
+		return 0, 0, false
+	}
+	return int(cx), int(cy), true
+}
+
+func setNativeOpacity(context any, opacity float64) {
+	ctx, ok := context.(driver.X11WindowContext)
+	if !ok || ctx.WindowHandle == 0 {
+		return
+	}
+	C.apm_set_opacity(C.ulong(ctx.WindowHandle), C.ulong(opacity*0xffffffff))
+}
+
+const clickThroughSupported = true
+
+func setNativeClickThrough(context any, on bool) {
+	ctx, ok := context.(driver.X11WindowContext)
+	if !ok || ctx.WindowHandle == 0 {
+		return
+	}
+	enable := C.int(0)
+	if on {
+		enable = 1
+	}
+	C.apm_set_click_through(C.ulong(ctx.WindowHandle), enable)
+}
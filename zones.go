@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// zoneAlpha keeps the tint faint enough to sit behind the text unnoticed
+// until it changes.
+const zoneAlpha = 48
+
+// Zone is an APM band starting at Min and running up to the next zone.
+type Zone struct {
+	Name  string `json:"name"`
+	Min   int    `json:"min_apm"`
+	Color string `json:"color"`
+}
+
+var defaultZones = []Zone{
+	{"Idle", 0, "#808080"},
+	{"Active", 60, "#30c050"},
+	{"Fast", 150, "#ff9900"},
+	{"Intense", 250, "#e03030"},
+}
+
+// parseZones reads one zone per line as "min color name", e.g.
+// "150 #ff9900 Fast", and orders them by threshold.
+func parseZones(text string) ([]Zone, error) {
+	var zones []Zone
+	for i, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: expected \"min_apm #rrggbb name\"", i+1)
+		}
+		minAPM, err := strconv.Atoi(fields[0])
+		if err != nil || minAPM < 0 {
+			return nil, fmt.Errorf("line %d: invalid APM %q", i+1, fields[0])
+		}
+		if _, err := parseHexColor(fields[1]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		zones = append(zones, Zone{strings.Join(fields[2:], " "), minAPM, fields[1]})
+	}
+	slices.SortStableFunc(zones, func(x, y Zone) int { return x.Min - y.Min })
+	return zones, nil
+}
+
+func formatZones(zones []Zone) string {
+	lines := make([]string, len(zones))
+	for i, z := range zones {
+		lines[i] = fmt.Sprintf("%d %s %s", z.Min, z.Color, z.Name)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (a *APMTracker) getZones() (bool, []Zone) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.zonesOn, a.zones
+}
+
+func (a *APMTracker) setZones(on bool, zones []Zone) {
+	a.mutex.Lock()
+	a.zonesOn = on
+	a.zones = zones
+	a.mutex.Unlock()
+	a.saveConfig()
+	a.updateZone(a.latestStats().Current)
+}
+
+// zoneFor returns the highest zone apm has reached.
+func zoneFor(zones []Zone, apm int) (Zone, bool) {
+	var zone Zone
+	found := false
+	for _, z := range zones {
+		if apm >= z.Min {
+			zone, found = z, true
+		}
+	}
+	return zone, found
+}
+
+// updateZone tints the main window's background with the current zone.
+func (a *APMTracker) updateZone(apm int) {
+	if a.zoneBg == nil {
+		return
+	}
+	var tint color.Color = color.Transparent
+	if on, zones := a.getZones(); on {
+		if zone, ok := zoneFor(zones, apm); ok {
+			if c, err := parseHexColor(zone.Color); err == nil {
+				r, g, b, _ := c.RGBA()
+				tint = color.NRGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), zoneAlpha}
+			}
+		}
+	}
+	if a.zoneBg.FillColor != tint {
+		a.zoneBg.FillColor = tint
+		a.zoneBg.Refresh()
+	}
+}